@@ -0,0 +1,182 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFailureThreshold is the default number of consecutive failures opening the circuit
+	DefaultFailureThreshold = 5
+
+	// DefaultCoolDown is the default time the circuit stays open before letting a trial call through
+	DefaultCoolDown = 30 * time.Second
+)
+
+// ErrOpen is returned instead of calling the external API while the circuit is open
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a Breaker
+type State int
+
+const (
+	// StateClosed lets every call through
+	StateClosed State = iota
+	// StateOpen rejects every call until the cool-down expires
+	StateOpen
+	// StateHalfOpen lets a single trial call through, its outcome closes or reopens the circuit
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// Settings configures a Breaker, zero values are replaced by the defaults
+type Settings struct {
+	// FailureThreshold is the number of consecutive failures opening the circuit
+	FailureThreshold int
+
+	// CoolDown is the time the circuit stays open before letting a trial call through
+	CoolDown time.Duration
+}
+
+// Status is a snapshot of a Breaker, suitable to be exposed by a status endpoint
+type Status struct {
+	Name                string     `json:"name"`
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	OpenedAt            *time.Time `json:"openedAt,omitempty"`
+}
+
+// Breaker is a concurrency safe circuit breaker.
+// It opens after FailureThreshold consecutive failures, then, once CoolDown has passed,
+// it lets a single trial call through: a success closes the circuit, a failure opens it again.
+type Breaker struct {
+	name     string
+	logger   *log.Logger
+	settings Settings
+
+	mu                  sync.Mutex
+	state               State
+	consecutiveFailures int
+	openedAt            time.Time
+	trialInFlight       bool
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// New returns a closed Breaker, name identifies it in logs and status
+func New(name string, logger *log.Logger, settings Settings) *Breaker {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = DefaultFailureThreshold
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = DefaultCoolDown
+	}
+	return &Breaker{
+		name:     name,
+		logger:   logger,
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+// Allow reports whether a call can be made, returning an error wrapping ErrOpen otherwise.
+// When the call is allowed, done must be called exactly once with its outcome.
+// Outcomes that say nothing about the health of the external API, such as a not found resource,
+// must be reported as not failed.
+func (b *Breaker) Allow() (done func(failed bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.settings.CoolDown {
+		b.transition(StateHalfOpen)
+	}
+
+	switch b.state {
+	case StateOpen:
+		return nil, fmt.Errorf("%s: %w", b.name, ErrOpen)
+	case StateHalfOpen:
+		if b.trialInFlight {
+			return nil, fmt.Errorf("%s: %w", b.name, ErrOpen)
+		}
+		b.trialInFlight = true
+		return b.onTrialDone, nil
+	}
+	return b.onDone, nil
+}
+
+// Status returns a snapshot of the breaker
+func (b *Breaker) Status() Status {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := Status{
+		Name:                b.name,
+		State:               b.state.String(),
+		ConsecutiveFailures: b.consecutiveFailures,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+	return status
+}
+
+func (b *Breaker) onDone(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	// a call allowed while closed can complete after the circuit has been opened by others
+	if b.state == StateClosed && b.consecutiveFailures >= b.settings.FailureThreshold {
+		b.open()
+	}
+}
+
+func (b *Breaker) onTrialDone(failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+	if failed {
+		b.consecutiveFailures++
+		b.open()
+		return
+	}
+	b.consecutiveFailures = 0
+	b.transition(StateClosed)
+}
+
+func (b *Breaker) open() {
+	b.openedAt = b.now()
+	b.transition(StateOpen)
+}
+
+func (b *Breaker) transition(state State) {
+	if b.state == state {
+		return
+	}
+	b.logger.Printf(
+		"circuit breaker %s: %s -> %s (consecutive failures: %d)",
+		b.name, b.state, state, b.consecutiveFailures,
+	)
+	b.state = state
+}
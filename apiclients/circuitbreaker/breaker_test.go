@@ -0,0 +1,97 @@
+package circuitbreaker
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(logs *bytes.Buffer) (*Breaker, *time.Time) {
+	now := time.Now()
+	breaker := New("pokeapi", log.New(logs, "", 0), Settings{FailureThreshold: 2, CoolDown: time.Minute})
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+// call makes a call through the breaker, reporting whether it was allowed
+func call(breaker *Breaker, failed bool) bool {
+	done, err := breaker.Allow()
+	if err != nil {
+		return false
+	}
+	done(failed)
+	return true
+}
+
+func TestBreaker(t *testing.T) {
+	t.Run("should open after the consecutive failures threshold", func(t *testing.T) {
+		var logs bytes.Buffer
+		breaker, _ := newTestBreaker(&logs)
+
+		call(breaker, true)
+		call(breaker, false)
+		call(breaker, true)
+		if found := breaker.Status().State; found != "closed" {
+			t.Errorf("found state %s; want closed since failures were not consecutive", found)
+		}
+
+		call(breaker, true)
+		if found := breaker.Status().State; found != "open" {
+			t.Errorf("found state %s; want open", found)
+		}
+		_, err := breaker.Allow()
+		if !errors.Is(err, ErrOpen) {
+			t.Errorf("received error %v; want %v", err, ErrOpen)
+		}
+		if !strings.Contains(logs.String(), "circuit breaker pokeapi: closed -> open") {
+			t.Errorf("found logs %q; want the transition logged", logs.String())
+		}
+	})
+
+	t.Run("should close after a successful trial call once the cool-down expires", func(t *testing.T) {
+		var logs bytes.Buffer
+		breaker, now := newTestBreaker(&logs)
+		call(breaker, true)
+		call(breaker, true)
+
+		*now = now.Add(time.Minute)
+
+		done, err := breaker.Allow()
+		if err != nil {
+			t.Fatalf("received error %v; want the trial call allowed", err)
+		}
+		if call(breaker, false) {
+			t.Errorf("a second call was allowed while half-open; want only the trial call")
+		}
+		done(false)
+
+		status := breaker.Status()
+		if status.State != "closed" || status.ConsecutiveFailures != 0 || status.OpenedAt != nil {
+			t.Errorf("found status %+v; want closed with no failures", status)
+		}
+		if !strings.Contains(logs.String(), "open -> half-open") || !strings.Contains(logs.String(), "half-open -> closed") {
+			t.Errorf("found logs %q; want every transition logged", logs.String())
+		}
+	})
+
+	t.Run("should reopen after a failed trial call", func(t *testing.T) {
+		var logs bytes.Buffer
+		breaker, now := newTestBreaker(&logs)
+		call(breaker, true)
+		call(breaker, true)
+
+		*now = now.Add(time.Minute)
+		call(breaker, true)
+
+		status := breaker.Status()
+		if status.State != "open" || !status.OpenedAt.Equal(*now) {
+			t.Errorf("found status %+v; want open since %s", status, *now)
+		}
+		if call(breaker, false) {
+			t.Errorf("a call was allowed; want the circuit open for a new cool-down")
+		}
+	})
+}
@@ -0,0 +1,3 @@
+// Package circuitbreaker provides a circuit breaker shared by the API clients,
+// to fail fast while an external API is down instead of waiting for every call to fail.
+package circuitbreaker
@@ -0,0 +1,3 @@
+// Package apiclients holds what is shared by the clients of the external APIs,
+// which live in the subpackages.
+package apiclients
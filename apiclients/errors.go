@@ -0,0 +1,83 @@
+package apiclients
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"malta895/pokedex/apiclients/retry"
+	"net/http"
+	"strings"
+)
+
+// bodySnippetLength is the maximum length of the response body kept in an UpstreamError
+const bodySnippetLength = 256
+
+// UpstreamError describes a failed call to an external API.
+// It wraps the cause of the failure, such as a sentinel error of the client or a transport error,
+// so that errors.Is and errors.As can be used on it.
+type UpstreamError struct {
+	// Service is the name of the external API, e.g. `pokeapi`
+	Service string
+
+	// URL is the URL of the failed request
+	URL string
+
+	// StatusCode is the status code of the response, 0 if no response has been received
+	StatusCode int
+
+	// Retryable reports whether the same call could succeed later, e.g. after a 503 or a connection error
+	Retryable bool
+
+	// BodySnippet is the beginning of the response body, useful to debug the failure
+	BodySnippet string
+
+	// Err is the cause of the failure
+	Err error
+}
+
+func (e *UpstreamError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: request to %s failed: %v", e.Service, e.URL, e.Err)
+	}
+	return fmt.Sprintf("%s: request to %s failed with status %d: %v", e.Service, e.URL, e.StatusCode, e.Err)
+}
+
+func (e *UpstreamError) Unwrap() error {
+	return e.Err
+}
+
+// NewStatusError returns the UpstreamError of a response with an unexpected status code, caused by err.
+// It reads the beginning of the response body, which must still be closed by the caller.
+func NewStatusError(service string, resp *http.Response, err error) *UpstreamError {
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, bodySnippetLength))
+	return &UpstreamError{
+		Service:     service,
+		URL:         resp.Request.URL.Redacted(),
+		StatusCode:  resp.StatusCode,
+		Retryable:   retry.IsTransientStatus(resp.StatusCode),
+		BodySnippet: strings.TrimSpace(string(snippet)),
+		Err:         err,
+	}
+}
+
+// NewBodyError returns the UpstreamError of a response to url, received with statusCode,
+// whose body cannot be read or does not hold the expected resource because of err
+func NewBodyError(service, url string, statusCode int, err error) *UpstreamError {
+	return &UpstreamError{
+		Service:    service,
+		URL:        url,
+		StatusCode: statusCode,
+		Err:        err,
+	}
+}
+
+// NewTransportError returns the UpstreamError of a request that received no response because of err
+func NewTransportError(service string, req *http.Request, err error) *UpstreamError {
+	return &UpstreamError{
+		Service:   service,
+		URL:       req.URL.Redacted(),
+		Retryable: !errors.Is(err, context.Canceled),
+		Err:       err,
+	}
+}
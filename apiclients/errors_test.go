@@ -0,0 +1,122 @@
+package apiclients
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var errSentinel = errors.New("sentinel")
+
+func TestNewStatusError(t *testing.T) {
+	tests := map[string]struct {
+		statusCode int
+		body       string
+
+		expectedRetryable bool
+		expectedSnippet   string
+	}{
+		"should report a 503 as retryable": {
+			statusCode: http.StatusServiceUnavailable,
+			body:       "Service Unavailable\n",
+
+			expectedRetryable: true,
+			expectedSnippet:   "Service Unavailable",
+		},
+		"should report a 404 as not retryable": {
+			statusCode: http.StatusNotFound,
+			body:       "Not Found",
+
+			expectedRetryable: false,
+			expectedSnippet:   "Not Found",
+		},
+		"should truncate long bodies": {
+			statusCode: http.StatusBadRequest,
+			body:       strings.Repeat("a", 1000),
+
+			expectedRetryable: false,
+			expectedSnippet:   strings.Repeat("a", bodySnippetLength),
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			recorder.WriteHeader(tt.statusCode)
+			recorder.WriteString(tt.body)
+			resp := recorder.Result()
+			resp.Request = httptest.NewRequest(http.MethodGet, "http://pokeapi.test/pokemon-species/pikachu", nil)
+
+			var err error = NewStatusError("pokeapi", resp, errSentinel)
+
+			if !errors.Is(err, errSentinel) {
+				t.Errorf("errors.Is(%v, errSentinel) = false; want true", err)
+			}
+			var upstreamErr *UpstreamError
+			if !errors.As(err, &upstreamErr) {
+				t.Fatalf("errors.As(%v, *UpstreamError) = false; want true", err)
+			}
+			if upstreamErr.StatusCode != tt.statusCode {
+				t.Errorf("found StatusCode=%d; want %d", upstreamErr.StatusCode, tt.statusCode)
+			}
+			if upstreamErr.Retryable != tt.expectedRetryable {
+				t.Errorf("found Retryable=%v; want %v", upstreamErr.Retryable, tt.expectedRetryable)
+			}
+			if upstreamErr.BodySnippet != tt.expectedSnippet {
+				t.Errorf("found BodySnippet=%q; want %q", upstreamErr.BodySnippet, tt.expectedSnippet)
+			}
+			if upstreamErr.URL != "http://pokeapi.test/pokemon-species/pikachu" {
+				t.Errorf("found URL=%s; want the request URL", upstreamErr.URL)
+			}
+		})
+	}
+}
+
+func TestNewTransportError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "http://pokeapi.test/pokemon-species/pikachu", nil)
+
+	t.Run("should report a connection error as retryable", func(t *testing.T) {
+		cause := &url.Error{Op: "Get", URL: req.URL.String(), Err: errors.New("connection refused")}
+		err := NewTransportError("pokeapi", req, cause)
+
+		if !err.Retryable || err.StatusCode != 0 {
+			t.Errorf("found %+v; want retryable without status code", err)
+		}
+	})
+
+	t.Run("should report a cancelled request as not retryable", func(t *testing.T) {
+		cause := &url.Error{Op: "Get", URL: req.URL.String(), Err: context.Canceled}
+		var err error = NewTransportError("pokeapi", req, cause)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("errors.Is(%v, context.Canceled) = false; want true", err)
+		}
+		if err.(*UpstreamError).Retryable {
+			t.Errorf("found Retryable=true; want false")
+		}
+	})
+}
+
+func TestNewBodyError(t *testing.T) {
+	t.Run("should report a malformed body as not retryable", func(t *testing.T) {
+		var err error = NewBodyError("pokeapi", "http://pokeapi.test/pokemon-species/pikachu", http.StatusOK, errSentinel)
+
+		if !errors.Is(err, errSentinel) {
+			t.Errorf("errors.Is(%v, errSentinel) = false; want true", err)
+		}
+		expected := &UpstreamError{
+			Service:    "pokeapi",
+			URL:        "http://pokeapi.test/pokemon-species/pikachu",
+			StatusCode: http.StatusOK,
+			Err:        errSentinel,
+		}
+		if !reflect.DeepEqual(err, expected) {
+			t.Errorf("found %+v; want %+v", err, expected)
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package funtranslations
+
+import (
+	"context"
+	"log"
+	"malta895/pokedex/cache"
+	"sync/atomic"
+)
+
+// cacheKeyPrefix namespaces the keys of the CachingClient, so that a Store can be shared
+const cacheKeyPrefix = "funtranslations:"
+
+// CachingClient is a Client remembering every successful translation of another Client,
+// so that translating the same text twice never hits the rate limited remote API.
+// Translations never expire, a persistent cache.Store makes them survive restarts.
+type CachingClient struct {
+	logger *log.Logger
+	next   Client
+	store  cache.Store
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewCachingClient returns a CachingClient decorating next
+func NewCachingClient(logger *log.Logger, next Client, store cache.Store) *CachingClient {
+	return &CachingClient{
+		logger: logger,
+		next:   next,
+		store:  store,
+	}
+}
+
+func (c *CachingClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	key := cacheKey(translatorType, text)
+	translated, ok, err := c.store.Get(key)
+	if err != nil {
+		c.logger.Printf("error reading %s translation from cache: %v", translatorType, err)
+	}
+	if ok {
+		c.hits.Add(1)
+		return string(translated), nil
+	}
+	c.misses.Add(1)
+
+	translation, err := c.next.FunTranslate(ctx, translatorType, text)
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.store.Set(key, []byte(translation), 0); err != nil {
+		// the translation is still valid, it will be retried on the next call
+		c.logger.Printf("error writing %s translation to cache: %v", translatorType, err)
+	}
+	return translation, nil
+}
+
+// Stats returns the hit and miss counters of the cache,
+// along with the entries and evictions of the store if it reports them
+func (c *CachingClient) Stats() cache.Stats {
+	stats := cache.StoreStats(c.store)
+	stats.Hits = c.hits.Load()
+	stats.Misses = c.misses.Load()
+	return stats
+}
+
+func cacheKey(translatorType, text string) string {
+	return cacheKeyPrefix + translatorType + ":" + text
+}
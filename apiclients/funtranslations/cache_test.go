@@ -0,0 +1,94 @@
+package funtranslations
+
+import (
+	"context"
+	"errors"
+	"log"
+	"malta895/pokedex/cache"
+	"testing"
+)
+
+type countingClient struct {
+	mockErr error
+	calls   int
+}
+
+func (cc *countingClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	cc.calls++
+	if cc.mockErr != nil {
+		return "", cc.mockErr
+	}
+	return translatorType + ": " + text, nil
+}
+
+func TestCachingClient(t *testing.T) {
+	t.Run("should translate each translator and text pair only once", func(t *testing.T) {
+		next := &countingClient{}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(0))
+
+		for i := 0; i < 2; i++ {
+			for _, translatorType := range []string{TranslatorYoda, TranslatorShakespeare} {
+				found, err := cachingClient.FunTranslate(context.Background(), translatorType, "some text")
+				if err != nil {
+					t.Errorf("received error %v; want nil", err)
+				}
+				if expected := translatorType + ": some text"; found != expected {
+					t.Errorf("found translation %s; want %s", found, expected)
+				}
+			}
+		}
+
+		if next.calls != 2 {
+			t.Errorf("found %d calls to the decorated client; want 2", next.calls)
+		}
+		expectedStats := cache.Stats{Hits: 2, Misses: 2, Entries: 2}
+		if found := cachingClient.Stats(); found != expectedStats {
+			t.Errorf("found stats %+v; want %+v", found, expectedStats)
+		}
+	})
+
+	t.Run("should not cache failed translations", func(t *testing.T) {
+		next := &countingClient{mockErr: ErrAPIStatusCode}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(0))
+
+		for i := 0; i < 2; i++ {
+			_, err := cachingClient.FunTranslate(context.Background(), TranslatorYoda, "some text")
+			if !errors.Is(err, ErrAPIStatusCode) {
+				t.Errorf("received error %v; want %v", err, ErrAPIStatusCode)
+			}
+		}
+
+		if next.calls != 2 {
+			t.Errorf("found %d calls to the decorated client; want 2", next.calls)
+		}
+	})
+
+	t.Run("should reload persisted translations without calling the decorated client", func(t *testing.T) {
+		dir := t.TempDir()
+		firstStore, err := cache.NewDiskStore(dir, 0)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		first := NewCachingClient(log.Default(), &countingClient{}, firstStore)
+		if _, err := first.FunTranslate(context.Background(), TranslatorYoda, "some text"); err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		next := &countingClient{mockErr: ErrAPIStatusCode}
+		restartedStore, err := cache.NewDiskStore(dir, 0)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		restarted := NewCachingClient(log.Default(), next, restartedStore)
+		found, err := restarted.FunTranslate(context.Background(), TranslatorYoda, "some text")
+		if err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if expected := "yoda: some text"; found != expected {
+			t.Errorf("found translation %s; want %s", found, expected)
+		}
+		if next.calls != 0 {
+			t.Errorf("found %d calls to the decorated client; want 0", next.calls)
+		}
+	})
+}
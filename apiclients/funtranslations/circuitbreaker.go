@@ -0,0 +1,44 @@
+package funtranslations
+
+import (
+	"context"
+	"errors"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/circuitbreaker"
+	"net/http"
+)
+
+type circuitBreakerClient struct {
+	next    Client
+	breaker *circuitbreaker.Breaker
+}
+
+// NewCircuitBreakerClient returns a Client failing fast with circuitbreaker.ErrOpen
+// while next keeps failing, e.g. because the API is down.
+// Unrecognized translators, rate limited and cancelled calls are not failures:
+// the rate limit is enforced by the QuotaTracker of the client instead.
+func NewCircuitBreakerClient(next Client, breaker *circuitbreaker.Breaker) Client {
+	return &circuitBreakerClient{next, breaker}
+}
+
+func (c *circuitBreakerClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return "", err
+	}
+	translation, err := c.next.FunTranslate(ctx, translatorType, text)
+	done(isFailure(err))
+	return translation, err
+}
+
+// isFailure reports whether err says the Fun Translations API is unhealthy
+func isFailure(err error) bool {
+	var upstreamErr *apiclients.UpstreamError
+	if errors.As(err, &upstreamErr) && upstreamErr.StatusCode == http.StatusTooManyRequests {
+		return false
+	}
+	return err != nil &&
+		!errors.Is(err, ErrUnrecognizedTranslator) &&
+		!errors.Is(err, ErrQuotaExhausted) &&
+		!errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,68 @@
+package funtranslations
+
+import (
+	"context"
+	"errors"
+	"log"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/circuitbreaker"
+	"net/http"
+	"testing"
+)
+
+func TestCircuitBreakerClient(t *testing.T) {
+	tests := map[string]struct {
+		mockErr error
+
+		expectedCalls int
+		expectedState string
+	}{
+		"should fail fast after consecutive api errors": {
+			mockErr: &apiclients.UpstreamError{StatusCode: http.StatusInternalServerError, Err: ErrAPIStatusCode},
+
+			expectedCalls: 2,
+			expectedState: "open",
+		},
+		"should not count a rate limited call as a failure": {
+			mockErr: &apiclients.UpstreamError{StatusCode: http.StatusTooManyRequests, Err: ErrAPIStatusCode},
+
+			expectedCalls: 3,
+			expectedState: "closed",
+		},
+		"should not count an unrecognized translator as a failure": {
+			mockErr: ErrUnrecognizedTranslator,
+
+			expectedCalls: 3,
+			expectedState: "closed",
+		},
+		"should not count a call refused by the quota as a failure": {
+			mockErr: ErrQuotaExhausted,
+
+			expectedCalls: 3,
+			expectedState: "closed",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			next := &countingClient{mockErr: tt.mockErr}
+			breaker := circuitbreaker.New("funtranslations", log.Default(), circuitbreaker.Settings{FailureThreshold: 2})
+			breakerClient := NewCircuitBreakerClient(next, breaker)
+
+			var err error
+			for i := 0; i < 3; i++ {
+				_, err = breakerClient.FunTranslate(context.Background(), TranslatorYoda, "some text")
+			}
+
+			if next.calls != tt.expectedCalls {
+				t.Errorf("found %d calls to the decorated client; want %d", next.calls, tt.expectedCalls)
+			}
+			if found := breaker.Status().State; found != tt.expectedState {
+				t.Errorf("found state %s; want %s", found, tt.expectedState)
+			}
+			if tt.expectedState == "open" && !errors.Is(err, circuitbreaker.ErrOpen) {
+				t.Errorf("received error %v; want %v", err, circuitbreaker.ErrOpen)
+			}
+		})
+	}
+}
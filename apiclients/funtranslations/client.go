@@ -2,56 +2,104 @@ package funtranslations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/apiclients/retry"
 	"net/http"
 	"net/url"
 )
 
 const (
+	// serviceName identifies the Fun Translations API in the errors returned by the client
+	serviceName = "funtranslations"
+
 	// funtranslationsBaseURL is the base URL of the `funtranslations` APIs
 	//
 	// Reference: https://funtranslations.com/api/
 	funtranslationsBaseURL = "https://api.funtranslations.com/translate"
 
-	shakespearePath = "shakespeare.json"
-	yodaPath        = "yoda.json"
-
 	// TranslatorYoda passed to FunTranslate will make it output a Yoda translation
 	TranslatorYoda = "yoda"
 
 	// TranslatorShakespeare passed to FunTranslate will make it output a Shakespeare translation
 	TranslatorShakespeare = "shakespeare"
+
+	// The other translators of the catalogue, see Translators
+	TranslatorPirate      = "pirate"
+	TranslatorMinion      = "minion"
+	TranslatorSith        = "sith"
+	TranslatorGungan      = "gungan"
+	TranslatorHuttese     = "huttese"
+	TranslatorMandalorian = "mandalorian"
+	TranslatorValyrian    = "valyrian"
+	TranslatorDothraki    = "dothraki"
+	TranslatorKlingon     = "klingon"
+	TranslatorVulcan      = "vulcan"
+	TranslatorPigLatin    = "pig-latin"
+	TranslatorMorse       = "morse"
 )
 
 var (
 	// ErrUnrecognizedTranslator is returned when the provided TranslatorType is not among the implemented ones
 	ErrUnrecognizedTranslator = errors.New("unrecognized translator type")
 	ErrAPIStatusCode          = errors.New("unexpected status code from remote api")
+
+	// ErrMalformedResponse is returned when the API responds with a body that holds no translation
+	ErrMalformedResponse = errors.New("malformed response from remote api")
 )
 
 type Client interface {
-	// FunTranslate given a Translator type and a text will output the translation
-	// only Yoda and Shakespeare translations are currently supported.
-	// Providing an unknown translatorType argument results in an error
-	FunTranslate(translatorType, text string) (string, error)
+	// FunTranslate given a Translator type and a text will output the translation,
+	// the supported translators are listed by Translators.
+	// Providing an unknown translatorType argument results in an error.
+	// The upstream call is aborted as soon as ctx is done.
+	FunTranslate(ctx context.Context, translatorType, text string) (string, error)
 }
 
 type client struct {
-	baseURL string
+	baseURL     string
+	httpClient  *http.Client
+	userAgent   string
+	headers     http.Header
+	retryPolicy retry.Policy
+	logger      *log.Logger
+	apiSecret   string
+	quota       *QuotaTracker
 }
 
-func NewClient() Client {
-	return &client{funtranslationsBaseURL}
+// NewClient returns a Client for the Fun Translations API,
+// by default it contacts `api.funtranslations.com` with a 10 seconds timeout.
+// Once the quota of requests reported by the API is exhausted, the calls fail with ErrQuotaExhausted
+// without contacting it, until the quota resets.
+func NewClient(opts ...Option) Client {
+	o := newOptions(opts)
+	httpOpts := httpclient.NewOptions(funtranslationsBaseURL, o.httpOptions...)
+	return &client{
+		baseURL:     httpOpts.BaseURL,
+		httpClient:  httpOpts.BuildHTTPClient(),
+		userAgent:   httpOpts.UserAgent,
+		headers:     httpOpts.Headers,
+		retryPolicy: httpOpts.RetryPolicy,
+		logger:      httpOpts.Logger,
+		apiSecret:   o.apiSecret,
+		quota:       o.quota,
+	}
 }
 
-func (c *client) FunTranslate(translatorType, text string) (string, error) {
+func (c *client) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
 	path, err := mapTranslatorToPath(translatorType)
 	if err != nil {
 		return "", err
 	}
+	if err := c.quota.allow(); err != nil {
+		return "", err
+	}
 	body := &translateReqBody{text}
 	bodyBytes, err := json.Marshal(body)
 	if err != nil {
@@ -61,25 +109,78 @@ func (c *client) FunTranslate(translatorType, text string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	resp, err := http.Post(reqURL, "application/json", bytes.NewReader(bodyBytes))
+	req, err := c.newRequest(ctx, http.MethodPost, reqURL, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return "", err
 	}
+	req.Header.Set("Content-Type", "application/json")
+	// translating the same text twice has no side effects, mark it as safe to retry
+	req.Header["Idempotency-Key"] = nil
+	retryPolicy := c.retryPolicy
+	retryPolicy.Veto = c.vetoRetry
+	resp, err := retryPolicy.Do(c.logger, c.httpClient, req)
+	if err != nil {
+		return "", apiclients.NewTransportError(serviceName, req, err)
+	}
+	defer resp.Body.Close()
+	c.quota.update(resp.Header)
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("%w: status %d", ErrAPIStatusCode, resp.StatusCode)
+		statusErr := apiclients.NewStatusError(serviceName, resp, ErrAPIStatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			c.quota.exhaust(resp.Header, statusErr.BodySnippet)
+		}
+		return "", statusErr
 	}
 	respBodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		upstreamErr := apiclients.NewBodyError(serviceName, req.URL.Redacted(), resp.StatusCode, fmt.Errorf("%w: %w", ErrMalformedResponse, err))
+		// the connection failed while reading, unlike a malformed body it may work next time
+		upstreamErr.Retryable = !errors.Is(err, context.Canceled)
+		return "", upstreamErr
 	}
 	respBody := &translateRespBody{}
 	if err := json.Unmarshal(respBodyBytes, respBody); err != nil {
-		return "", err
+		return "", apiclients.NewBodyError(serviceName, req.URL.Redacted(), resp.StatusCode, fmt.Errorf("%w: %w", ErrMalformedResponse, err))
+	}
+	if respBody.Contents.Translated == "" && text != "" {
+		return "", apiclients.NewBodyError(serviceName, req.URL.Redacted(), resp.StatusCode, fmt.Errorf("%w: no translated text", ErrMalformedResponse))
 	}
 
 	return respBody.Contents.Translated, nil
 }
 
+// vetoRetry records the quota reported by every response about to be retried,
+// and prevents retrying a rate limited response unless it carries a Retry-After header:
+// blind retries would only consume the quota further
+func (c *client) vetoRetry(resp *http.Response) bool {
+	c.quota.update(resp.Header)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	_, ok := retry.ParseRetryAfter(resp.Header.Get("Retry-After"))
+	return !ok
+}
+
+// newRequest builds a request carrying the configured user agent and headers
+func (c *client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.apiSecret != "" {
+		req.Header.Set(apiSecretHeader, c.apiSecret)
+	}
+	return req, nil
+}
+
 // translateReqBody is a partial representation of the request body of the funtranslations API
 type translateReqBody struct {
 	Text string `json:"text"`
@@ -91,13 +192,3 @@ type translateRespBody struct {
 		Translated string `json:"translated"`
 	} `json:"contents"`
 }
-
-func mapTranslatorToPath(translatorType string) (string, error) {
-	if translatorType == TranslatorYoda {
-		return yodaPath, nil
-	}
-	if translatorType == TranslatorShakespeare {
-		return shakespearePath, nil
-	}
-	return "", ErrUnrecognizedTranslator
-}
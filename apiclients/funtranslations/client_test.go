@@ -1,14 +1,19 @@
 package funtranslations
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/apiclients/retry"
 	"malta895/pokedex/testutils"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFunTranslate(t *testing.T) {
@@ -37,7 +42,7 @@ func TestFunTranslate(t *testing.T) {
 				}
 			  }`,
 
-			expectedTranslatorPath: yodaPath,
+			expectedTranslatorPath: "yoda.json",
 			expectedTranslation:    "Some translation, this is",
 			expectedError:          nil,
 			expectAPICalled:        true,
@@ -56,11 +61,45 @@ func TestFunTranslate(t *testing.T) {
 				}
 			  }`,
 
-			expectedTranslatorPath: shakespearePath,
+			expectedTranslatorPath: "shakespeare.json",
 			expectedTranslation:    "Ye art mr. Luca",
 			expectedError:          nil,
 			expectAPICalled:        true,
 		},
+		"should respond with the translation of any translator of the catalogue": {
+			translatorType: TranslatorPirate,
+			inputText:      "Hello friend",
+			mockAPIResponse: `{
+				"contents": {
+				  "translated": "Ahoy matey",
+				  "text": "Hello friend",
+				  "translation": "pirate"
+				}
+			  }`,
+
+			expectedTranslatorPath: "pirate.json",
+			expectedTranslation:    "Ahoy matey",
+			expectedError:          nil,
+			expectAPICalled:        true,
+		},
+		"should respond with error if the api responds with an invalid body": {
+			translatorType:  TranslatorYoda,
+			inputText:       "this is some translation",
+			mockAPIResponse: `<html>Service Unavailable</html>`,
+
+			expectedTranslatorPath: "yoda.json",
+			expectedError:          ErrMalformedResponse,
+			expectAPICalled:        true,
+		},
+		"should respond with error if the api responds with no translation": {
+			translatorType:  TranslatorYoda,
+			inputText:       "this is some translation",
+			mockAPIResponse: `{"success": {"total": 1}}`,
+
+			expectedTranslatorPath: "yoda.json",
+			expectedError:          ErrMalformedResponse,
+			expectAPICalled:        true,
+		},
 		"should return correct error if translator type is not recognized": {
 			translatorType: "unknownTranslatorType",
 			inputText:      "You are Mr. Luca",
@@ -80,7 +119,7 @@ func TestFunTranslate(t *testing.T) {
 			  }`,
 			nonOKStatusCode: http.StatusTooManyRequests,
 
-			expectedTranslatorPath: shakespearePath,
+			expectedTranslatorPath: "shakespeare.json",
 			expectedTranslation:    "",
 			expectedError:          ErrAPIStatusCode,
 			expectAPICalled:        true,
@@ -96,7 +135,7 @@ func TestFunTranslate(t *testing.T) {
 			  }`,
 			nonOKStatusCode: http.StatusInternalServerError,
 
-			expectedTranslatorPath: shakespearePath,
+			expectedTranslatorPath: "shakespeare.json",
 			expectedTranslation:    "",
 			expectedError:          ErrAPIStatusCode,
 			expectAPICalled:        true,
@@ -121,9 +160,9 @@ func TestFunTranslate(t *testing.T) {
 				},
 			)
 			defer server.Close()
-			pokemonClient := &client{server.URL}
+			pokemonClient := NewClient(WithHTTPOptions(httpclient.WithBaseURL(server.URL)))
 
-			foundTranslation, err := pokemonClient.FunTranslate(tt.translatorType, tt.inputText)
+			foundTranslation, err := pokemonClient.FunTranslate(context.Background(), tt.translatorType, tt.inputText)
 			if !errors.Is(err, tt.expectedError) {
 				t.Errorf(
 					"received error %v; want %v",
@@ -141,6 +180,66 @@ func TestFunTranslate(t *testing.T) {
 	}
 }
 
+func TestFunTranslateContextCancellation(t *testing.T) {
+	t.Run("should abort the upstream call when the context is cancelled", func(t *testing.T) {
+		unblock := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-unblock
+		}))
+		defer server.Close()
+		defer close(unblock)
+		translationsClient := NewClient(WithHTTPOptions(httpclient.WithBaseURL(server.URL)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		foundTranslation, err := translationsClient.FunTranslate(ctx, TranslatorYoda, "this is slow")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("received error %v; want %v", err, context.DeadlineExceeded)
+		}
+		if foundTranslation != "" {
+			t.Errorf("found translation %s; want empty", foundTranslation)
+		}
+	})
+}
+
+func TestFunTranslateUpstreamError(t *testing.T) {
+	tests := map[string]struct {
+		mockAPIResponse string
+	}{
+		"should describe a malformed body with an UpstreamError": {
+			mockAPIResponse: `<html>Service Unavailable</html>`,
+		},
+		"should describe a missing translation with an UpstreamError": {
+			mockAPIResponse: `{"success": {"total": 1}}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := mockFunTranslationsServer(t, "yoda.json", tt.mockAPIResponse, "some text", http.StatusOK, func() {})
+			defer server.Close()
+			translationsClient := NewClient(WithHTTPOptions(httpclient.WithBaseURL(server.URL)))
+
+			_, err := translationsClient.FunTranslate(context.Background(), TranslatorYoda, "some text")
+
+			var upstreamErr *apiclients.UpstreamError
+			if !errors.As(err, &upstreamErr) {
+				t.Fatalf("received error %v; want an UpstreamError", err)
+			}
+			if !errors.Is(err, ErrMalformedResponse) {
+				t.Errorf("received error %v; want %v", err, ErrMalformedResponse)
+			}
+			if expectedURL := server.URL + "/yoda.json"; upstreamErr.URL != expectedURL {
+				t.Errorf("found URL %s; want %s", upstreamErr.URL, expectedURL)
+			}
+			if upstreamErr.StatusCode != http.StatusOK || upstreamErr.Retryable {
+				t.Errorf("found status code %d and retryable %v; want %d and false", upstreamErr.StatusCode, upstreamErr.Retryable, http.StatusOK)
+			}
+		})
+	}
+}
+
 func mockFunTranslationsServer(
 	t *testing.T,
 	translatorPath, mockResp, expectedInputText string,
@@ -188,3 +287,122 @@ func TestNew(t *testing.T) {
 		}
 	})
 }
+
+func TestNewWithOptions(t *testing.T) {
+	t.Run("requests should carry the configured user agent and headers", func(t *testing.T) {
+		var foundUserAgent, foundHeader string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			foundUserAgent = r.Header.Get("User-Agent")
+			foundHeader = r.Header.Get("X-Mirror-Token")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"contents": {"translated": "Some text, this is"}}`))
+		}))
+		defer server.Close()
+
+		opts := WithHTTPOptions(
+			httpclient.WithBaseURL(server.URL),
+			httpclient.WithUserAgent("pokedex-test"),
+			httpclient.WithHeader("X-Mirror-Token", "secret"),
+		)
+		if _, err := NewClient(opts).FunTranslate(context.Background(), TranslatorYoda, "some text"); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if foundUserAgent != "pokedex-test" {
+			t.Errorf("found User-Agent=%s; want pokedex-test", foundUserAgent)
+		}
+		if foundHeader != "secret" {
+			t.Errorf("found X-Mirror-Token=%s; want secret", foundHeader)
+		}
+	})
+
+	t.Run("requests should carry the api secret", func(t *testing.T) {
+		var foundSecret string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			foundSecret = r.Header.Get("X-Funtranslations-Api-Secret")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"contents": {"translated": "Some text, this is"}}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(WithHTTPOptions(httpclient.WithBaseURL(server.URL)), WithAPISecret("some-secret"))
+		if _, err := client.FunTranslate(context.Background(), TranslatorYoda, "some text"); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if foundSecret != "some-secret" {
+			t.Errorf("found X-Funtranslations-Api-Secret=%s; want some-secret", foundSecret)
+		}
+	})
+}
+
+func TestFunTranslateRetries(t *testing.T) {
+	t.Run("should retry a rate limited translation sending the same text", func(t *testing.T) {
+		var calls int
+		server := mockFunTranslationsServer(
+			t,
+			"yoda.json",
+			`{"contents": {"translated": "Retried, this text is"}}`,
+			"this text is retried",
+			http.StatusOK,
+			func() {
+				calls++
+			},
+		)
+		defer server.Close()
+		rateLimited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls == 0 {
+				calls++
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			server.Config.Handler.ServeHTTP(w, r)
+		}))
+		defer rateLimited.Close()
+
+		translationsClient := NewClient(
+			WithHTTPOptions(
+				httpclient.WithBaseURL(rateLimited.URL),
+				httpclient.WithRetryPolicy(retry.Policy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+			),
+		)
+		found, err := translationsClient.FunTranslate(context.Background(), TranslatorYoda, "this text is retried")
+		if err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if found != "Retried, this text is" {
+			t.Errorf("found translation %s; want Retried, this text is", found)
+		}
+		if calls != 2 {
+			t.Errorf("found %d calls; want 2", calls)
+		}
+	})
+
+	t.Run("should not retry a rate limited translation without Retry-After", func(t *testing.T) {
+		var calls int
+		rateLimited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer rateLimited.Close()
+
+		quota := NewQuotaTracker()
+		translationsClient := NewClient(
+			WithHTTPOptions(
+				httpclient.WithBaseURL(rateLimited.URL),
+				httpclient.WithRetryPolicy(retry.Policy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+			),
+			WithQuotaTracker(quota),
+		)
+		_, err := translationsClient.FunTranslate(context.Background(), TranslatorYoda, "this text is not retried")
+		if !errors.Is(err, ErrAPIStatusCode) {
+			t.Errorf("received error %v; want %v", err, ErrAPIStatusCode)
+		}
+		if calls != 1 {
+			t.Errorf("found %d calls; want 1", calls)
+		}
+		if !quota.Status().Exhausted {
+			t.Errorf("found quota %+v; want it exhausted", quota.Status())
+		}
+	})
+}
@@ -1,3 +1,4 @@
 // Package funtranslations provides a client for the Fun Translations API.
-// It also provides a way to map the API response to the application data structures.
+// It also provides a way to map the API response to the application data structures,
+// and offline Yoda and Shakespeare translators that can replace the API or take over when it fails.
 package funtranslations
@@ -0,0 +1,37 @@
+package funtranslations
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+type fallbackClient struct {
+	logger   *log.Logger
+	primary  Client
+	fallback Client
+}
+
+// NewFallbackClient returns a Client translating with fallback whenever primary fails,
+// e.g. the offline translators when the Fun Translations API rate limit has been reached.
+// Cancelled calls are not retried. When the fallback fails too, the error of primary stays the main cause:
+// it is returned alone if fallback does not support the translator, joined with the one of fallback otherwise.
+func NewFallbackClient(logger *log.Logger, primary, fallback Client) Client {
+	return &fallbackClient{logger, primary, fallback}
+}
+
+func (c *fallbackClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	translation, err := c.primary.FunTranslate(ctx, translatorType, text)
+	if err == nil || ctx.Err() != nil {
+		return translation, err
+	}
+	c.logger.Printf("error translating with the %s translator, falling back: %v", translatorType, err)
+	translation, fallbackErr := c.fallback.FunTranslate(ctx, translatorType, text)
+	if fallbackErr == nil {
+		return translation, nil
+	}
+	if errors.Is(fallbackErr, ErrUnrecognizedTranslator) {
+		return "", err
+	}
+	return "", errors.Join(err, fallbackErr)
+}
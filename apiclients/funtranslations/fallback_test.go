@@ -0,0 +1,79 @@
+package funtranslations
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+)
+
+func TestFallbackClient(t *testing.T) {
+	tests := map[string]struct {
+		primaryErr error
+		cancelled  bool
+
+		expectedText          string
+		expectedErr           error
+		expectedFallbackCalls int
+	}{
+		"should translate with the primary client": {
+			expectedText: "yoda: It is some text.",
+		},
+		"should translate with the fallback client when the primary one fails": {
+			primaryErr: ErrAPIStatusCode,
+
+			expectedText:          "Some text, it is.",
+			expectedFallbackCalls: 1,
+		},
+		"should not fall back when the call is cancelled": {
+			primaryErr: context.Canceled,
+			cancelled:  true,
+
+			expectedErr: context.Canceled,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			primary := &countingClient{mockErr: tt.primaryErr}
+			fallback := &recordingClient{next: NewOfflineClient()}
+			client := NewFallbackClient(log.Default(), primary, fallback)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+			found, err := client.FunTranslate(ctx, TranslatorYoda, "It is some text.")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("received error %v; want %v", err, tt.expectedErr)
+			}
+			if found != tt.expectedText {
+				t.Errorf("found translation %q; want %q", found, tt.expectedText)
+			}
+			if fallback.calls != tt.expectedFallbackCalls {
+				t.Errorf("found %d calls to the fallback client; want %d", fallback.calls, tt.expectedFallbackCalls)
+			}
+		})
+	}
+
+	t.Run("should keep the primary error if the fallback client does not support the translator", func(t *testing.T) {
+		primary := &countingClient{mockErr: ErrQuotaExhausted}
+		client := NewFallbackClient(log.Default(), primary, NewOfflineClient())
+
+		_, err := client.FunTranslate(context.Background(), TranslatorPirate, "It is some text.")
+		if !errors.Is(err, ErrQuotaExhausted) || errors.Is(err, ErrUnrecognizedTranslator) {
+			t.Errorf("received error %v; want only %v", err, ErrQuotaExhausted)
+		}
+	})
+}
+
+type recordingClient struct {
+	next  Client
+	calls int
+}
+
+func (rc *recordingClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	rc.calls++
+	return rc.next.FunTranslate(ctx, translatorType, text)
+}
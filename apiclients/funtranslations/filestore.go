@@ -0,0 +1,136 @@
+package funtranslations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"malta895/pokedex/cache"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileStore is a cache.Store for the CachingClient keeping every translation in a single JSON file,
+// mapping translator type to original text to translated text.
+// It backs the deprecated FUNTRANSLATIONS_CACHE_FILE setting, a cache.DiskStore should be preferred.
+type FileStore struct {
+	path string
+
+	mu sync.Mutex
+	// translations maps translator type to original text to translated text
+	translations map[string]map[string]string
+}
+
+// NewFileStore returns a FileStore persisting the translations to path,
+// loading the ones already stored there
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		path:         path,
+		translations: make(map[string]map[string]string),
+	}
+
+	fileBytes, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read translations cache file: %w", err)
+	}
+	if err := json.Unmarshal(fileBytes, &s.translations); err != nil {
+		return nil, fmt.Errorf("malformed translations cache file %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(key string) ([]byte, bool, error) {
+	translatorType, text, err := parseCacheKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	translated, ok := s.translations[translatorType][text]
+	if !ok {
+		return nil, false, nil
+	}
+	return []byte(translated), true, nil
+}
+
+// Set stores value for key and writes back the whole file.
+// Translations never expire, so ttl is ignored.
+func (s *FileStore) Set(key string, value []byte, _ time.Duration) error {
+	translatorType, text, err := parseCacheKey(key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.translations[translatorType] == nil {
+		s.translations[translatorType] = make(map[string]string)
+	}
+	s.translations[translatorType][text] = string(value)
+	return s.persist()
+}
+
+func (s *FileStore) Delete(key string) error {
+	translatorType, text, err := parseCacheKey(key)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.translations[translatorType][text]; !ok {
+		return nil
+	}
+	delete(s.translations[translatorType], text)
+	return s.persist()
+}
+
+func (s *FileStore) TTL(key string) (time.Duration, bool, error) {
+	_, ok, err := s.Get(key)
+	return 0, ok, err
+}
+
+// Stats returns the number of stored translations
+func (s *FileStore) Stats() cache.Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var stats cache.Stats
+	for _, byText := range s.translations {
+		stats.Entries += len(byText)
+	}
+	return stats
+}
+
+// persist atomically replaces the file with the current translations, s.mu must be held
+func (s *FileStore) persist() error {
+	fileBytes, err := json.Marshal(s.translations)
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(fileBytes); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), s.path)
+}
+
+// parseCacheKey is the inverse of cacheKey
+func parseCacheKey(key string) (translatorType, text string, err error) {
+	translatorType, text, ok := strings.Cut(strings.TrimPrefix(key, cacheKeyPrefix), ":")
+	if !ok || !strings.HasPrefix(key, cacheKeyPrefix) {
+		return "", "", fmt.Errorf("unexpected translations cache key %q", key)
+	}
+	return translatorType, text, nil
+}
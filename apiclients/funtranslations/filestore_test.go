@@ -0,0 +1,66 @@
+package funtranslations
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	t.Run("should load the translations persisted by the previous versions", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "translations.json")
+		if err := os.WriteFile(path, []byte(`{"yoda":{"some text":"some text, yoda says"}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		store, err := NewFileStore(path)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		next := &countingClient{}
+		cachingClient := NewCachingClient(log.Default(), next, store)
+		found, err := cachingClient.FunTranslate(context.Background(), TranslatorYoda, "some text")
+		if err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if expected := "some text, yoda says"; found != expected {
+			t.Errorf("found translation %s; want %s", found, expected)
+		}
+		if next.calls != 0 {
+			t.Errorf("found %d calls to the decorated client; want 0", next.calls)
+		}
+	})
+
+	t.Run("should write new translations back to the file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "translations.json")
+		store, err := NewFileStore(path)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		cachingClient := NewCachingClient(log.Default(), &countingClient{}, store)
+		if _, err := cachingClient.FunTranslate(context.Background(), TranslatorShakespeare, "a: text"); err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		found, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		if expected := `{"shakespeare":{"a: text":"shakespeare: a: text"}}`; string(found) != expected {
+			t.Errorf("found file %s; want %s", found, expected)
+		}
+	})
+
+	t.Run("should refuse a malformed file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "translations.json")
+		if err := os.WriteFile(path, []byte(`["yoda"]`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := NewFileStore(path); err == nil {
+			t.Errorf("received nil error; want a malformed file error")
+		}
+	})
+}
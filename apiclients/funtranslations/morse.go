@@ -0,0 +1,37 @@
+package funtranslations
+
+import (
+	"strings"
+	"unicode"
+)
+
+// morseCodes maps the characters of the International Morse code to their code
+var morseCodes = map[rune]string{
+	'a': ".-", 'b': "-...", 'c': "-.-.", 'd': "-..", 'e': ".", 'f': "..-.", 'g': "--.", 'h': "....",
+	'i': "..", 'j': ".---", 'k': "-.-", 'l': ".-..", 'm': "--", 'n': "-.", 'o': "---", 'p': ".--.",
+	'q': "--.-", 'r': ".-.", 's': "...", 't': "-", 'u': "..-", 'v': "...-", 'w': ".--", 'x': "-..-",
+	'y': "-.--", 'z': "--..",
+	'0': "-----", '1': ".----", '2': "..---", '3': "...--", '4': "....-",
+	'5': ".....", '6': "-....", '7': "--...", '8': "---..", '9': "----.",
+	'.': ".-.-.-", ',': "--..--", '?': "..--..", '\'': ".----.", '!': "-.-.--", '/': "-..-.",
+	'(': "-.--.", ')': "-.--.-", '&': ".-...", ':': "---...", ';': "-.-.-.", '=': "-...-",
+	'+': ".-.-.", '-': "-....-", '"': ".-..-.", '@': ".--.-.",
+}
+
+// translateMorse encodes text in International Morse code, separating the letters by a space
+// and the words by a slash; the characters with no code, e.g. accented letters, are dropped
+func translateMorse(text string) string {
+	var words []string
+	for _, word := range strings.Fields(text) {
+		var codes []string
+		for _, r := range word {
+			if code, ok := morseCodes[unicode.ToLower(r)]; ok {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) > 0 {
+			words = append(words, strings.Join(codes, " "))
+		}
+	}
+	return strings.Join(words, " / ")
+}
@@ -0,0 +1,97 @@
+package funtranslations
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// offlineTranslators maps the translators implemented offline to their implementation
+var offlineTranslators = map[string]func(text string) string{
+	TranslatorYoda:        translateYoda,
+	TranslatorShakespeare: translateShakespeare,
+	TranslatorPigLatin:    translatePigLatin,
+	TranslatorMorse:       translateMorse,
+}
+
+type offlineClient struct{}
+
+// NewOfflineClient returns a Client translating locally, without contacting the Fun Translations API,
+// e.g. for environments with no internet access. Its translations are rougher than the remote ones,
+// but deterministic; the translators it lacks fail with ErrUnrecognizedTranslator.
+func NewOfflineClient() Client {
+	return offlineClient{}
+}
+
+func (offlineClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	translate, ok := offlineTranslators[translatorType]
+	if !ok {
+		return "", ErrUnrecognizedTranslator
+	}
+	return translate(text), nil
+}
+
+// token is a word, or the run of characters between two words, of a text
+type token struct {
+	text   string
+	isWord bool
+}
+
+// tokenize splits text into words, made of letters and apostrophes, and the runs of characters between them,
+// so that joining the tokens gives back text
+func tokenize(text string) []token {
+	var tokens []token
+	var current strings.Builder
+	currentIsWord := false
+	for _, r := range text {
+		isWord := unicode.IsLetter(r) || r == '\''
+		if current.Len() > 0 && isWord != currentIsWord {
+			tokens = append(tokens, token{current.String(), currentIsWord})
+			current.Reset()
+		}
+		current.WriteRune(r)
+		currentIsWord = isWord
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, token{current.String(), currentIsWord})
+	}
+	return tokens
+}
+
+// matchCase returns replacement capitalized like original
+func matchCase(original, replacement string) string {
+	if original == "" || replacement == "" {
+		return replacement
+	}
+	if first := []rune(original)[0]; unicode.IsUpper(first) {
+		return capitalize(replacement)
+	}
+	return replacement
+}
+
+// capitalize upper cases the first letter of s, e.g. "'tis" becomes "'Tis"
+func capitalize(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsLetter(r) {
+			runes[i] = unicode.ToUpper(r)
+			break
+		}
+	}
+	return string(runes)
+}
+
+// uncapitalize lower cases the first letter of s
+func uncapitalize(s string) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsLetter(r) {
+			runes[i] = unicode.ToLower(r)
+			break
+		}
+	}
+	return string(runes)
+}
@@ -0,0 +1,116 @@
+package funtranslations
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOfflineClient(t *testing.T) {
+	tests := map[string]struct {
+		translatorType string
+		text           string
+
+		expectedText string
+		expectedErr  error
+	}{
+		"should move the object before the subject for yoda": {
+			translatorType: TranslatorYoda,
+			text:           "It is very strong. It can shoot fire from its tail!",
+
+			expectedText: "Very strong, it is. Shoot fire from its tail, it can!",
+		},
+		"should keep the proper nouns capitalized for yoda": {
+			translatorType: TranslatorYoda,
+			text:           "Pikachu can generate electricity.",
+
+			expectedText: "Generate electricity, Pikachu can.",
+		},
+		"should leave the subordinate clauses in place for yoda": {
+			translatorType: TranslatorYoda,
+			text:           "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+
+			expectedText: "When several of these POKéMON gather, build and cause lightning storms, their electricity could.",
+		},
+		"should leave the sentences with no known verb in place for yoda": {
+			translatorType: TranslatorYoda,
+			text:           "This Pokémon stores electricity in its cheeks.",
+
+			expectedText: "This Pokémon stores electricity in its cheeks.",
+		},
+		"should replace the modern words for shakespeare": {
+			translatorType: TranslatorShakespeare,
+			text:           "You are often here, and your enemies are never quick.",
+
+			expectedText: "Thou art oft hither, and thy foes art ne'er quick.",
+		},
+		"should replace the phrases for shakespeare": {
+			translatorType: TranslatorShakespeare,
+			text:           "it is very strong.",
+
+			expectedText: "'tis most strong.",
+		},
+		"should capitalize the replaced phrases for shakespeare": {
+			translatorType: TranslatorShakespeare,
+			text:           "It was created by a scientist.",
+
+			expectedText: "'Twas created by a scientist.",
+		},
+		"should give the verbs of the third person the eth ending for shakespeare": {
+			translatorType: TranslatorShakespeare,
+			text:           "It stores electricity, she uses it and it shoots, as it always does.",
+
+			expectedText: "It storeth electricity, she useth it and it shooteth, as it always doth.",
+		},
+		"should move the leading consonants for pig latin": {
+			translatorType: TranslatorPigLatin,
+			text:           "Pikachu is quick, it shocks everyone!",
+
+			expectedText: "Ikachupay isway ickquay, itway ocksshay everyoneway!",
+		},
+		"should move the leading consonants by runes for pig latin": {
+			translatorType: TranslatorPigLatin,
+			text:           "Ñandú ȺȺa Ñ",
+
+			expectedText: "Andúñay Aⱥⱥay Ñay",
+		},
+		"should encode the text for morse": {
+			translatorType: TranslatorMorse,
+			text:           "SOS, Pikachu!",
+
+			expectedText: "... --- ... --..-- / .--. .. -.- .- -.-. .... ..- -.-.--",
+		},
+		"should fail for a translator with no offline implementation": {
+			translatorType: TranslatorPirate,
+			text:           "some text",
+
+			expectedErr: ErrUnrecognizedTranslator,
+		},
+		"should fail for an unrecognized translator": {
+			translatorType: "unknown",
+			text:           "some text",
+
+			expectedErr: ErrUnrecognizedTranslator,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			found, err := NewOfflineClient().FunTranslate(context.Background(), tt.translatorType, tt.text)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("received error %v; want %v", err, tt.expectedErr)
+			}
+			if found != tt.expectedText {
+				t.Errorf("found translation %q; want %q", found, tt.expectedText)
+			}
+		})
+	}
+
+	t.Run("should abort if the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := NewOfflineClient().FunTranslate(ctx, TranslatorYoda, "some text"); !errors.Is(err, context.Canceled) {
+			t.Errorf("received error %v; want %v", err, context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,46 @@
+package funtranslations
+
+import "malta895/pokedex/apiclients/httpclient"
+
+// Option customizes the Client returned by NewClient
+type Option func(*options)
+
+type options struct {
+	httpOptions []httpclient.Option
+	apiSecret   string
+	quota       *QuotaTracker
+}
+
+// WithHTTPOptions customizes the http client contacting the API,
+// e.g. with httpclient.WithBaseURL to contact an instance other than `api.funtranslations.com`
+func WithHTTPOptions(opts ...httpclient.Option) Option {
+	return func(o *options) {
+		o.httpOptions = append(o.httpOptions, opts...)
+	}
+}
+
+// WithAPISecret authenticates every request with the secret of a paid plan of the API, lifting the public rate limit
+func WithAPISecret(secret string) Option {
+	return func(o *options) {
+		o.apiSecret = secret
+	}
+}
+
+// WithQuotaTracker makes the client record the quota of requests reported by the API in quota,
+// e.g. to report it; by default the client tracks it on its own
+func WithQuotaTracker(quota *QuotaTracker) Option {
+	return func(o *options) {
+		o.quota = quota
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.quota == nil {
+		o.quota = NewQuotaTracker()
+	}
+	return o
+}
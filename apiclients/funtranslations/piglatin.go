@@ -0,0 +1,40 @@
+package funtranslations
+
+import (
+	"slices"
+	"strings"
+)
+
+// translatePigLatin moves the leading consonants of every word of text to its end, followed by "ay",
+// e.g. "pikachu" becomes "ikachupay"; the words starting with a vowel get "way" instead
+func translatePigLatin(text string) string {
+	var translated strings.Builder
+	for _, tok := range tokenize(text) {
+		if !tok.isWord {
+			translated.WriteString(tok.text)
+			continue
+		}
+		translated.WriteString(pigLatinWord(tok.text))
+	}
+	return translated.String()
+}
+
+func pigLatinWord(word string) string {
+	// the word is rotated by runes of its lower cased form, whose byte length may differ from word's
+	lower := []rune(strings.ToLower(word))
+	vowel := slices.IndexFunc(lower, func(r rune) bool { return strings.ContainsRune("aeiou", r) })
+	switch {
+	case vowel == 0:
+		return word + "way"
+	case vowel < 0:
+		// e.g. "by", "rhythm"
+		vowel = max(slices.Index(lower, 'y'), 1)
+		if vowel >= len(lower) {
+			return word + "ay"
+		}
+	case lower[vowel] == 'u' && lower[vowel-1] == 'q':
+		// "qu" moves as a whole, e.g. "quick" becomes "ickquay"
+		vowel++
+	}
+	return matchCase(word, string(lower[vowel:])+string(lower[:vowel])) + "ay"
+}
@@ -0,0 +1,209 @@
+package funtranslations
+
+import (
+	"errors"
+	"fmt"
+	"malta895/pokedex/apiclients/retry"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// apiSecretHeader carries the secret of the authenticated requests
+	apiSecretHeader = "X-Funtranslations-Api-Secret"
+
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+
+	// defaultQuotaWindow is how long a rate limited client waits when the API does not say it,
+	// the quotas of the API are hourly
+	defaultQuotaWindow = time.Hour
+
+	// minUnixResetTime tells the X-RateLimit-Reset headers holding a unix time from the ones holding seconds
+	minUnixResetTime = 1_000_000_000
+)
+
+// ErrQuotaExhausted is returned without contacting the API while its quota of requests is exhausted
+var ErrQuotaExhausted = errors.New("funtranslations quota exhausted")
+
+var (
+	// rateLimitMessage matches the limit in the message of the 429 responses,
+	// e.g. "Rate limit of 10 requests per hour exceeded"
+	rateLimitMessage = regexp.MustCompile(`(?i)rate limit of (\d+) requests`)
+
+	// waitMessage matches the parts of the wait in the message of the 429 responses,
+	// e.g. "Please wait for 44 minutes and 58 seconds"
+	waitMessage = regexp.MustCompile(`(?i)(\d+) (hour|minute|second)s?`)
+)
+
+// QuotaTracker keeps the quota of requests to the Fun Translations API, as reported by its responses.
+// It is safe for concurrent use.
+type QuotaTracker struct {
+	mu sync.Mutex
+
+	// limit and remaining are negative until a response reports them
+	limit     int
+	remaining int
+	resetAt   time.Time
+	updatedAt time.Time
+
+	now func() time.Time
+}
+
+// QuotaStatus is the quota of requests to the Fun Translations API
+type QuotaStatus struct {
+	// Limit and Remaining are nil until a response reports them
+	Limit     *int       `json:"limit,omitempty"`
+	Remaining *int       `json:"remaining,omitempty"`
+	ResetAt   *time.Time `json:"resetAt,omitempty"`
+
+	// Exhausted reports whether the calls are refused locally, until ResetAt
+	Exhausted bool       `json:"exhausted"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// NewQuotaTracker returns a QuotaTracker with an unknown quota
+func NewQuotaTracker() *QuotaTracker {
+	return &QuotaTracker{limit: -1, remaining: -1, now: time.Now}
+}
+
+// Status returns the current quota
+func (q *QuotaTracker) Status() QuotaStatus {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.expire(now)
+	var status QuotaStatus
+	if q.limit >= 0 {
+		limit := q.limit
+		status.Limit = &limit
+	}
+	if q.remaining >= 0 {
+		remaining := q.remaining
+		status.Remaining = &remaining
+	}
+	if !q.resetAt.IsZero() {
+		resetAt := q.resetAt
+		status.ResetAt = &resetAt
+	}
+	if !q.updatedAt.IsZero() {
+		updatedAt := q.updatedAt
+		status.UpdatedAt = &updatedAt
+	}
+	status.Exhausted = q.exhausted(now)
+	return status
+}
+
+// allow returns ErrQuotaExhausted while the quota is exhausted
+func (q *QuotaTracker) allow() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.expire(now)
+	if q.exhausted(now) {
+		return fmt.Errorf("%w until %s", ErrQuotaExhausted, q.resetAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// update records the quota reported by the X-RateLimit-* headers of a response
+func (q *QuotaTracker) update(header http.Header) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	limit, hasLimit := headerInt(header, rateLimitLimitHeader)
+	remaining, hasRemaining := headerInt(header, rateLimitRemainingHeader)
+	reset, hasReset := headerInt(header, rateLimitResetHeader)
+	if !hasLimit && !hasRemaining && !hasReset {
+		return
+	}
+	if hasLimit {
+		q.limit = limit
+	}
+	if hasRemaining {
+		q.remaining = remaining
+	}
+	if hasReset {
+		q.resetAt = resetTime(now, reset)
+	}
+	q.updatedAt = now
+}
+
+// exhaust records a rate limited response, whose quota resets after the wait it asks for,
+// read from its Retry-After or X-RateLimit-Reset headers, or from the message of its body
+func (q *QuotaTracker) exhaust(header http.Header, body string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	q.remaining = 0
+	q.updatedAt = now
+	if matches := rateLimitMessage.FindStringSubmatch(body); matches != nil && q.limit < 0 {
+		q.limit, _ = strconv.Atoi(matches[1])
+	}
+
+	if wait, ok := retry.ParseRetryAfter(header.Get("Retry-After")); ok {
+		q.resetAt = now.Add(wait)
+	} else if reset, ok := headerInt(header, rateLimitResetHeader); ok {
+		q.resetAt = resetTime(now, reset)
+	} else if wait, ok := parseWait(body); ok {
+		q.resetAt = now.Add(wait)
+	} else {
+		q.resetAt = now.Add(defaultQuotaWindow)
+	}
+}
+
+// expire forgets an exhausted quota once its reset time has passed
+func (q *QuotaTracker) expire(now time.Time) {
+	if q.remaining == 0 && !q.resetAt.IsZero() && !now.Before(q.resetAt) {
+		q.remaining = q.limit
+		q.resetAt = time.Time{}
+	}
+}
+
+func (q *QuotaTracker) exhausted(now time.Time) bool {
+	return q.remaining == 0 && now.Before(q.resetAt)
+}
+
+// headerInt returns the non-negative integer value of the header key, reporting false if it is missing or invalid
+func headerInt(header http.Header, key string) (int, bool) {
+	value, err := strconv.Atoi(strings.TrimSpace(header.Get(key)))
+	if err != nil || value < 0 {
+		return 0, false
+	}
+	return value, true
+}
+
+// resetTime interprets the value of a X-RateLimit-Reset header,
+// either a unix time or the seconds left until the reset
+func resetTime(now time.Time, reset int) time.Time {
+	if reset >= minUnixResetTime {
+		return time.Unix(int64(reset), 0)
+	}
+	return now.Add(time.Duration(reset) * time.Second)
+}
+
+// parseWait returns the wait asked for by the message of a rate limited response,
+// e.g. 44m58s for "Please wait for 44 minutes and 58 seconds"
+func parseWait(body string) (time.Duration, bool) {
+	_, message, found := strings.Cut(strings.ToLower(body), "wait for")
+	if !found {
+		return 0, false
+	}
+	units := map[string]time.Duration{"hour": time.Hour, "minute": time.Minute, "second": time.Second}
+	var wait time.Duration
+	matches := waitMessage.FindAllStringSubmatch(message, -1)
+	for _, match := range matches {
+		amount, _ := strconv.Atoi(match[1])
+		wait += time.Duration(amount) * units[match[2]]
+	}
+	return wait, len(matches) > 0
+}
@@ -0,0 +1,190 @@
+package funtranslations
+
+import (
+	"context"
+	"errors"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/apiclients/retry"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuotaTracker(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	rateLimitedBody := `{"error": {"code": 429, "message": "Too Many Requests: Rate limit of 10 requests per hour exceeded. ` +
+		`Please wait for 44 minutes and 58 seconds."}}`
+
+	tests := map[string]struct {
+		header      http.Header
+		rateLimited bool
+		body        string
+
+		expectedLimit     int
+		expectedRemaining int
+		expectedResetAt   time.Time
+		expectedExhausted bool
+	}{
+		"should read the quota from the headers": {
+			header: http.Header{
+				"X-Ratelimit-Limit":     {"60"},
+				"X-Ratelimit-Remaining": {"12"},
+				"X-Ratelimit-Reset":     {"600"},
+			},
+
+			expectedLimit:     60,
+			expectedRemaining: 12,
+			expectedResetAt:   now.Add(10 * time.Minute),
+		},
+		"should read a reset header holding a unix time": {
+			header: http.Header{
+				"X-Ratelimit-Remaining": {"0"},
+				"X-Ratelimit-Reset":     {"1714559400"},
+			},
+
+			expectedLimit:     -1,
+			expectedRemaining: 0,
+			expectedResetAt:   time.Unix(1714559400, 0),
+			expectedExhausted: true,
+		},
+		"should read the wait of a rate limited response from its body": {
+			rateLimited: true,
+			body:        rateLimitedBody,
+
+			expectedLimit:     10,
+			expectedRemaining: 0,
+			expectedResetAt:   now.Add(44*time.Minute + 58*time.Second),
+			expectedExhausted: true,
+		},
+		"should prefer the Retry-After header of a rate limited response": {
+			header:      http.Header{"Retry-After": {"30"}},
+			rateLimited: true,
+			body:        rateLimitedBody,
+
+			expectedLimit:     10,
+			expectedRemaining: 0,
+			expectedResetAt:   now.Add(30 * time.Second),
+			expectedExhausted: true,
+		},
+		"should wait an hour when a rate limited response does not say how long": {
+			rateLimited: true,
+
+			expectedLimit:     -1,
+			expectedRemaining: 0,
+			expectedResetAt:   now.Add(time.Hour),
+			expectedExhausted: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			quota := NewQuotaTracker()
+			quota.now = func() time.Time { return now }
+
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			quota.update(header)
+			if tt.rateLimited {
+				quota.exhaust(header, tt.body)
+			}
+
+			status := quota.Status()
+			if found := intOrNegative(status.Limit); found != tt.expectedLimit {
+				t.Errorf("found limit=%d; want %d", found, tt.expectedLimit)
+			}
+			if found := intOrNegative(status.Remaining); found != tt.expectedRemaining {
+				t.Errorf("found remaining=%d; want %d", found, tt.expectedRemaining)
+			}
+			if status.ResetAt == nil || !status.ResetAt.Equal(tt.expectedResetAt) {
+				t.Errorf("found resetAt=%v; want %s", status.ResetAt, tt.expectedResetAt)
+			}
+			if status.Exhausted != tt.expectedExhausted {
+				t.Errorf("found exhausted=%t; want %t", status.Exhausted, tt.expectedExhausted)
+			}
+			if err := quota.allow(); errors.Is(err, ErrQuotaExhausted) != tt.expectedExhausted {
+				t.Errorf("received error %v; want exhausted=%t", err, tt.expectedExhausted)
+			}
+		})
+	}
+
+	t.Run("should allow the calls again after the reset", func(t *testing.T) {
+		quota := NewQuotaTracker()
+		quota.now = func() time.Time { return now }
+		quota.update(http.Header{"X-Ratelimit-Limit": {"5"}, "X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"60"}})
+		if err := quota.allow(); !errors.Is(err, ErrQuotaExhausted) {
+			t.Fatalf("received error %v; want %v", err, ErrQuotaExhausted)
+		}
+
+		quota.now = func() time.Time { return now.Add(time.Minute) }
+		if err := quota.allow(); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if remaining := intOrNegative(quota.Status().Remaining); remaining != 5 {
+			t.Errorf("found remaining=%d; want 5", remaining)
+		}
+	})
+}
+
+func TestFunTranslateQuota(t *testing.T) {
+	t.Run("should refuse the calls locally once the quota is exhausted", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Header().Set("X-RateLimit-Limit", "5")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", "3600")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"contents": {"translated": "Translated, this text is"}}`))
+		}))
+		defer server.Close()
+
+		quota := NewQuotaTracker()
+		client := NewClient(
+			WithHTTPOptions(httpclient.WithBaseURL(server.URL), httpclient.WithRetryPolicy(retry.Policy{MaxAttempts: 1})),
+			WithQuotaTracker(quota),
+		)
+		if _, err := client.FunTranslate(context.Background(), TranslatorYoda, "this text is translated"); err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		if _, err := client.FunTranslate(context.Background(), TranslatorYoda, "this text is translated"); !errors.Is(err, ErrQuotaExhausted) {
+			t.Errorf("received error %v; want %v", err, ErrQuotaExhausted)
+		}
+		if calls != 1 {
+			t.Errorf("found %d calls to the API; want 1", calls)
+		}
+		if !quota.Status().Exhausted {
+			t.Errorf("found exhausted=false; want true")
+		}
+	})
+
+	t.Run("should refuse the calls locally after a rate limited response", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": {"code": 429, "message": "Too Many Requests: Please wait for 10 minutes."}}`))
+		}))
+		defer server.Close()
+
+		client := NewClient(WithHTTPOptions(httpclient.WithBaseURL(server.URL), httpclient.WithRetryPolicy(retry.Policy{MaxAttempts: 1})))
+		if _, err := client.FunTranslate(context.Background(), TranslatorYoda, "some text"); !errors.Is(err, ErrAPIStatusCode) {
+			t.Fatalf("received error %v; want %v", err, ErrAPIStatusCode)
+		}
+		if _, err := client.FunTranslate(context.Background(), TranslatorYoda, "some text"); !errors.Is(err, ErrQuotaExhausted) {
+			t.Errorf("received error %v; want %v", err, ErrQuotaExhausted)
+		}
+		if calls != 1 {
+			t.Errorf("found %d calls to the API; want 1", calls)
+		}
+	})
+}
+
+func intOrNegative(value *int) int {
+	if value == nil {
+		return -1
+	}
+	return *value
+}
@@ -0,0 +1,111 @@
+package funtranslations
+
+import (
+	"strings"
+)
+
+// shakespearePhrases are the sequences of words replaced as a whole, before the single words
+var shakespearePhrases = map[string]string{
+	"you are":   "thou art",
+	"are you":   "art thou",
+	"you have":  "thou hast",
+	"have you":  "hast thou",
+	"you will":  "thou wilt",
+	"you can":   "thou canst",
+	"you do":    "thou dost",
+	"it is":     "'tis",
+	"it was":    "'twas",
+	"over here": "hither",
+}
+
+// shakespeareWords are the words replaced one by one
+var shakespeareWords = map[string]string{
+	"you":      "thou",
+	"your":     "thy",
+	"yours":    "thine",
+	"yourself": "thyself",
+	"are":      "art",
+	"has":      "hath",
+	"does":     "doth",
+	"before":   "ere",
+	"often":    "oft",
+	"over":     "o'er",
+	"never":    "ne'er",
+	"ever":     "e'er",
+	"nothing":  "naught",
+	"yes":      "aye",
+	"here":     "hither",
+	"there":    "thither",
+	"where":    "whither",
+	"why":      "wherefore",
+	"maybe":    "perchance",
+	"perhaps":  "perchance",
+	"very":     "most",
+	"enemy":    "foe",
+	"enemies":  "foes",
+	"quickly":  "swiftly",
+	"until":    "till",
+	"between":  "betwixt",
+	"among":    "amongst",
+}
+
+// shakespeareThirdPerson are the subjects whose verb takes the archaic -eth ending, e.g. "it runneth"
+var shakespeareThirdPerson = map[string]bool{
+	"it": true, "he": true, "she": true, "this": true, "that": true, "who": true,
+}
+
+// shakespeareNotVerbs are the words ending in s following a third person subject that are not verbs
+var shakespeareNotVerbs = map[string]bool{
+	"is": true, "was": true, "has": true, "does": true, "its": true, "his": true, "as": true, "always": true,
+}
+
+// translateShakespeare replaces the modern words and phrases of text with their Elizabethan counterparts,
+// and gives the archaic -eth ending to the verbs of the third person, e.g. "it stores" becomes "it storeth"
+func translateShakespeare(text string) string {
+	tokens := tokenize(text)
+	var translated strings.Builder
+	// previousWord is the last word written, in lowercase, or empty after punctuation
+	var previousWord string
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if !tok.isWord {
+			translated.WriteString(tok.text)
+			if strings.TrimSpace(tok.text) != "" {
+				previousWord = ""
+			}
+			continue
+		}
+
+		// a phrase is two words separated by a single space
+		if i+2 < len(tokens) && tokens[i+1].text == " " && tokens[i+2].isWord {
+			phrase := strings.ToLower(tok.text + " " + tokens[i+2].text)
+			if replacement, ok := shakespearePhrases[phrase]; ok {
+				translated.WriteString(matchCase(tok.text, replacement))
+				previousWord = strings.ToLower(tokens[i+2].text)
+				i += 2
+				continue
+			}
+		}
+
+		word := strings.ToLower(tok.text)
+		replacement := tok.text
+		if w, ok := shakespeareWords[word]; ok {
+			replacement = matchCase(tok.text, w)
+		} else if shakespeareThirdPerson[previousWord] {
+			replacement = matchCase(tok.text, thirdPersonVerb(word))
+		}
+		translated.WriteString(replacement)
+		previousWord = word
+	}
+	return translated.String()
+}
+
+// thirdPersonVerb gives the -eth ending to word if it looks like a verb of the third person, e.g. "stores"
+func thirdPersonVerb(word string) string {
+	if len(word) < 4 || shakespeareNotVerbs[word] || !strings.HasSuffix(word, "s") || strings.HasSuffix(word, "ss") {
+		return word
+	}
+	stem := strings.TrimSuffix(word, "s")
+	stem = strings.TrimSuffix(stem, "e")
+	return stem + "eth"
+}
@@ -0,0 +1,24 @@
+package funtranslations
+
+import (
+	"context"
+	"malta895/pokedex/apiclients/singleflight"
+)
+
+type singleflightClient struct {
+	next         Client
+	translations singleflight.Group[string]
+}
+
+// NewSingleflightClient returns a Client sharing a single call to next among the concurrent translations
+// of the same text, so that they consume a single request of the rate limit
+func NewSingleflightClient(next Client) Client {
+	return &singleflightClient{next: next}
+}
+
+func (c *singleflightClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	translation, _, err := c.translations.Do(ctx, cacheKey(translatorType, text), func(ctx context.Context) (string, error) {
+		return c.next.FunTranslate(ctx, translatorType, text)
+	})
+	return translation, err
+}
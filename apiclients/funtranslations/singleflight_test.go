@@ -0,0 +1,78 @@
+package funtranslations
+
+import (
+	"context"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/testutils"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSingleflightClient(t *testing.T) {
+	t.Run("should make a single upstream call for concurrent translations of the same text", func(t *testing.T) {
+		var hits atomic.Int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			<-release
+			w.Write([]byte(`{"contents": {"translated": "Pikachu, a mouse it is"}}`))
+		}))
+		defer server.Close()
+		client := NewSingleflightClient(NewClient(WithHTTPOptions(httpclient.WithBaseURL(server.URL))))
+
+		const callers = 20
+		var wg, joined sync.WaitGroup
+		joined.Add(callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			ctx := testutils.WaitingContext(context.Background(), joined.Done)
+			go func() {
+				defer wg.Done()
+				translation, err := client.FunTranslate(ctx, TranslatorYoda, "Pikachu is a mouse")
+				if err != nil {
+					t.Errorf("received error %v; want nil", err)
+				}
+				if translation != "Pikachu, a mouse it is" {
+					t.Errorf("found translation %s; want Pikachu, a mouse it is", translation)
+				}
+			}()
+		}
+		// every caller joined the call in flight once it waits for its result
+		joined.Wait()
+		close(release)
+		wg.Wait()
+
+		if found := hits.Load(); found != 1 {
+			t.Errorf("found %d requests; want 1", found)
+		}
+	})
+
+	t.Run("should make separate upstream calls for different translators", func(t *testing.T) {
+		var hits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			w.Write([]byte(`{"contents": {"translated": "translated"}}`))
+		}))
+		defer server.Close()
+		client := NewSingleflightClient(NewClient(WithHTTPOptions(httpclient.WithBaseURL(server.URL))))
+
+		var wg sync.WaitGroup
+		for _, translator := range []string{TranslatorYoda, TranslatorShakespeare} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.FunTranslate(context.Background(), translator, "Pikachu is a mouse"); err != nil {
+					t.Errorf("received error %v; want nil", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if found := hits.Load(); found != 2 {
+			t.Errorf("found %d requests; want 2", found)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package funtranslations
+
+import (
+	"slices"
+)
+
+// Translator describes a translator of the Fun Translations API
+type Translator struct {
+	// Name identifies the translator, it is the translatorType passed to FunTranslate
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+
+	// Path is the endpoint of the translator, relative to the base URL of the API
+	Path string `json:"path"`
+
+	// Offline reports whether the translator is also implemented by the offline Client
+	Offline bool `json:"offline"`
+}
+
+// catalogue lists the translators known to the client, in the order they are presented
+var catalogue = []Translator{
+	{Name: TranslatorYoda, DisplayName: "Yoda", Path: "yoda.json"},
+	{Name: TranslatorShakespeare, DisplayName: "Shakespeare", Path: "shakespeare.json"},
+	{Name: TranslatorPirate, DisplayName: "Pirate", Path: "pirate.json"},
+	{Name: TranslatorMinion, DisplayName: "Minion", Path: "minion.json"},
+	{Name: TranslatorSith, DisplayName: "Sith", Path: "sith.json"},
+	{Name: TranslatorGungan, DisplayName: "Gungan", Path: "gungan.json"},
+	{Name: TranslatorHuttese, DisplayName: "Huttese", Path: "huttese.json"},
+	{Name: TranslatorMandalorian, DisplayName: "Mandalorian", Path: "mandalorian.json"},
+	{Name: TranslatorValyrian, DisplayName: "High Valyrian", Path: "valyrian.json"},
+	{Name: TranslatorDothraki, DisplayName: "Dothraki", Path: "dothraki.json"},
+	{Name: TranslatorKlingon, DisplayName: "Klingon", Path: "klingon.json"},
+	{Name: TranslatorVulcan, DisplayName: "Vulcan", Path: "vulcan.json"},
+	{Name: TranslatorPigLatin, DisplayName: "Pig Latin", Path: "pig-latin.json"},
+	{Name: TranslatorMorse, DisplayName: "Morse code", Path: "morse.json"},
+}
+
+// Translators returns the translators known to the client
+func Translators() []Translator {
+	translators := slices.Clone(catalogue)
+	for i := range translators {
+		_, translators[i].Offline = offlineTranslators[translators[i].Name]
+	}
+	return translators
+}
+
+// LookupTranslator returns the translator named name, reporting false if it is unknown
+func LookupTranslator(name string) (Translator, bool) {
+	for _, translator := range Translators() {
+		if translator.Name == name {
+			return translator, true
+		}
+	}
+	return Translator{}, false
+}
+
+// SupportedTranslators returns the translator types accepted by FunTranslate
+func SupportedTranslators() []string {
+	names := make([]string, 0, len(catalogue))
+	for _, translator := range catalogue {
+		names = append(names, translator.Name)
+	}
+	return names
+}
+
+func mapTranslatorToPath(translatorType string) (string, error) {
+	translator, ok := LookupTranslator(translatorType)
+	if !ok {
+		return "", ErrUnrecognizedTranslator
+	}
+	return translator.Path, nil
+}
@@ -0,0 +1,50 @@
+package funtranslations
+
+import (
+	"testing"
+)
+
+func TestLookupTranslator(t *testing.T) {
+	tests := map[string]struct {
+		name string
+
+		expected   Translator
+		expectedOK bool
+	}{
+		"should find a translator implemented offline": {
+			name: TranslatorYoda,
+
+			expected:   Translator{Name: "yoda", DisplayName: "Yoda", Path: "yoda.json", Offline: true},
+			expectedOK: true,
+		},
+		"should find a translator only implemented by the API": {
+			name: TranslatorPirate,
+
+			expected:   Translator{Name: "pirate", DisplayName: "Pirate", Path: "pirate.json"},
+			expectedOK: true,
+		},
+		"should not find an unknown translator": {
+			name: "unknown",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			found, ok := LookupTranslator(tt.name)
+			if ok != tt.expectedOK {
+				t.Errorf("found ok=%t; want %t", ok, tt.expectedOK)
+			}
+			if found != tt.expected {
+				t.Errorf("found translator=%+v; want %+v", found, tt.expected)
+			}
+		})
+	}
+
+	t.Run("should implement offline only translators of the catalogue", func(t *testing.T) {
+		for name := range offlineTranslators {
+			if _, ok := LookupTranslator(name); !ok {
+				t.Errorf("found offline translator %s; want it in the catalogue", name)
+			}
+		}
+	})
+}
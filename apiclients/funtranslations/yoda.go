@@ -0,0 +1,104 @@
+package funtranslations
+
+import (
+	"strings"
+)
+
+// yodaVerbs are the verbs a clause is split at, moving what follows them to its start,
+// e.g. "it is very strong" becomes "very strong, it is"
+var yodaVerbs = map[string]bool{
+	"is": true, "are": true, "was": true, "were": true, "am": true,
+	"can": true, "could": true, "will": true, "would": true, "shall": true, "should": true,
+	"may": true, "might": true, "must": true, "has": true, "have": true, "had": true,
+	"does": true, "do": true, "did": true, "becomes": true, "become": true, "seems": true,
+}
+
+// yodaLowercased are the words that lose their capital letter when they no longer start the sentence
+var yodaLowercased = map[string]bool{
+	"it": true, "its": true, "this": true, "that": true, "these": true, "those": true,
+	"the": true, "a": true, "an": true, "they": true, "their": true, "he": true, "she": true,
+	"his": true, "her": true, "we": true, "our": true, "you": true, "your": true, "there": true,
+}
+
+// yodaSubordinators start the clauses that are left in place, since their verb is not the main one
+var yodaSubordinators = map[string]bool{
+	"when": true, "if": true, "because": true, "while": true, "as": true, "although": true,
+	"though": true, "since": true, "unless": true, "until": true, "once": true, "where": true,
+	"whenever": true, "after": true, "before": true, "and": true, "but": true, "or": true,
+}
+
+// yodaMaxSubjectWords bounds the words before the verb of a reordered clause, longer ones are left in place
+const yodaMaxSubjectWords = 4
+
+// translateYoda reorders the clauses of every sentence of text from subject-verb-object to object-subject-verb,
+// e.g. "It is very strong." becomes "Very strong, it is."
+func translateYoda(text string) string {
+	var sentences []string
+	for _, sentence := range splitSentences(text) {
+		sentences = append(sentences, yodaSentence(sentence))
+	}
+	return strings.Join(sentences, " ")
+}
+
+// splitSentences splits text after every sentence-ending punctuation mark, dropping the surrounding whitespace
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i, r := range text {
+		if r == '.' || r == '!' || r == '?' {
+			// consecutive marks, e.g. "?!", end the same sentence
+			if next := i + 1; next < len(text) && strings.ContainsRune(".!?", rune(text[next])) {
+				continue
+			}
+			if sentence := strings.TrimSpace(text[start : i+1]); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			start = i + 1
+		}
+	}
+	if sentence := strings.TrimSpace(text[start:]); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+func yodaSentence(sentence string) string {
+	body := strings.TrimRight(sentence, ".!?")
+	punctuation := sentence[len(body):]
+
+	clauses := strings.Split(body, ", ")
+	for i, clause := range clauses {
+		clauses[i] = yodaClause(clause, i == 0)
+	}
+	return strings.Join(clauses, ", ") + punctuation
+}
+
+// yodaClause moves what follows the first verb of clause before its subject,
+// leaving in place the clauses it does not recognize
+func yodaClause(clause string, startsSentence bool) string {
+	words := strings.Fields(clause)
+	if len(words) < 3 || yodaSubordinators[strings.ToLower(words[0])] {
+		return clause
+	}
+	verb := -1
+	for i, word := range words[:min(len(words), yodaMaxSubjectWords+1)] {
+		if yodaVerbs[strings.ToLower(word)] {
+			verb = i
+			break
+		}
+	}
+	// a clause with no subject, or nothing to move, is left in place
+	if verb < 1 || verb == len(words)-1 {
+		return clause
+	}
+
+	subject := words[:verb]
+	moved := words[verb+1:]
+	if startsSentence {
+		moved[0] = capitalize(moved[0])
+		if yodaLowercased[strings.ToLower(subject[0])] {
+			subject[0] = uncapitalize(subject[0])
+		}
+	}
+	return strings.Join(moved, " ") + ", " + strings.Join(subject, " ") + " " + words[verb]
+}
@@ -0,0 +1,3 @@
+// Package httpclient provides the options of the http clients shared by the API clients,
+// such as the base URL of the API, the timeout and the headers of every request.
+package httpclient
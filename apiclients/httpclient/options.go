@@ -0,0 +1,115 @@
+package httpclient
+
+import (
+	"log"
+	"malta895/pokedex/apiclients/retry"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout is the timeout of the http.Client used when none is provided
+const DefaultTimeout = 10 * time.Second
+
+// Option customizes the Options of an API client
+type Option func(*Options)
+
+// Options are the settings of the http client of an API client
+type Options struct {
+	BaseURL     string
+	HTTPClient  *http.Client
+	Transport   http.RoundTripper
+	Timeout     time.Duration
+	UserAgent   string
+	Headers     http.Header
+	RetryPolicy retry.Policy
+	Logger      *log.Logger
+}
+
+// WithBaseURL makes the client contact an instance of the API other than the public one,
+// e.g. an internal mirror or a local stand-in
+func WithBaseURL(baseURL string) Option {
+	return func(o *Options) {
+		o.BaseURL = baseURL
+	}
+}
+
+// WithHTTPClient makes the client perform requests with the given http.Client.
+// The provided client is never modified, WithTransport and WithTimeout apply to a copy of it.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *Options) {
+		o.HTTPClient = httpClient
+	}
+}
+
+// WithTransport overrides the transport of the http.Client
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *Options) {
+		o.Transport = transport
+	}
+}
+
+// WithTimeout overrides the timeout of the http.Client
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *Options) {
+		o.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request
+func WithUserAgent(userAgent string) Option {
+	return func(o *Options) {
+		o.UserAgent = userAgent
+	}
+}
+
+// WithHeader adds a header to every request, it can be provided multiple times
+func WithHeader(key, value string) Option {
+	return func(o *Options) {
+		o.Headers.Add(key, value)
+	}
+}
+
+// WithRetryPolicy overrides retry.DefaultPolicy, used to retry the requests failing with transient errors
+func WithRetryPolicy(policy retry.Policy) Option {
+	return func(o *Options) {
+		o.RetryPolicy = policy
+	}
+}
+
+// WithLogger sets the logger used to report retries, log.Default() is used otherwise
+func WithLogger(logger *log.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
+// NewOptions returns the Options resulting from applying opts to the defaults,
+// contacting the API at defaultBaseURL unless WithBaseURL is provided
+func NewOptions(defaultBaseURL string, opts ...Option) Options {
+	o := Options{
+		BaseURL:     defaultBaseURL,
+		Headers:     http.Header{},
+		RetryPolicy: retry.DefaultPolicy(),
+		Logger:      log.Default(),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// BuildHTTPClient returns a copy of the configured http.Client with transport and timeout applied
+func (o Options) BuildHTTPClient() *http.Client {
+	httpClient := &http.Client{Timeout: DefaultTimeout}
+	if o.HTTPClient != nil {
+		clientCopy := *o.HTTPClient
+		httpClient = &clientCopy
+	}
+	if o.Transport != nil {
+		httpClient.Transport = o.Transport
+	}
+	if o.Timeout > 0 {
+		httpClient.Timeout = o.Timeout
+	}
+	return httpClient
+}
@@ -0,0 +1,72 @@
+package httpclient
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewOptions(t *testing.T) {
+	t.Run("should default to the given base url", func(t *testing.T) {
+		found := NewOptions("https://api.example.com").BaseURL
+
+		if found != "https://api.example.com" {
+			t.Errorf("found BaseURL=%s; want https://api.example.com", found)
+		}
+	})
+
+	t.Run("should apply the options in order", func(t *testing.T) {
+		found := NewOptions(
+			"https://api.example.com",
+			WithBaseURL("http://mirror.local"),
+			WithUserAgent("pokedex-test"),
+			WithHeader("X-Mirror-Token", "first"),
+			WithHeader("X-Mirror-Token", "second"),
+		)
+
+		if found.BaseURL != "http://mirror.local" {
+			t.Errorf("found BaseURL=%s; want http://mirror.local", found.BaseURL)
+		}
+		if found.UserAgent != "pokedex-test" {
+			t.Errorf("found UserAgent=%s; want pokedex-test", found.UserAgent)
+		}
+		if values := found.Headers.Values("X-Mirror-Token"); len(values) != 2 {
+			t.Errorf("found X-Mirror-Token=%v; want [first second]", values)
+		}
+	})
+}
+
+func TestBuildHTTPClient(t *testing.T) {
+	t.Run("transport and timeout should not modify the provided http client", func(t *testing.T) {
+		httpClient := &http.Client{Timeout: time.Minute}
+		transport := &http.Transport{}
+
+		found := NewOptions(
+			"https://api.example.com",
+			WithHTTPClient(httpClient),
+			WithTransport(transport),
+			WithTimeout(time.Second),
+		).BuildHTTPClient()
+
+		if found == httpClient {
+			t.Errorf("found the provided http client; want a copy")
+		}
+		if found.Transport != transport {
+			t.Errorf("found transport %v; want %v", found.Transport, transport)
+		}
+		if found.Timeout != time.Second {
+			t.Errorf("found timeout %s; want %s", found.Timeout, time.Second)
+		}
+		if httpClient.Timeout != time.Minute || httpClient.Transport != nil {
+			t.Errorf("provided http client has been modified")
+		}
+	})
+
+	t.Run("should default to a client with a timeout", func(t *testing.T) {
+		found := NewOptions("https://api.example.com").BuildHTTPClient()
+
+		if found.Timeout != DefaultTimeout {
+			t.Errorf("found timeout %s; want %s", found.Timeout, DefaultTimeout)
+		}
+	})
+}
@@ -0,0 +1,215 @@
+package pokeapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"malta895/pokedex/cache"
+	"malta895/pokedex/types"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is the default lifetime of a cached species,
+	// species data basically never changes
+	DefaultCacheTTL = 24 * time.Hour
+
+	// DefaultCacheNotFoundTTL is the default lifetime of a cached ErrPokemonNotFound
+	DefaultCacheNotFoundTTL = time.Hour
+
+	// cacheKeyPrefix namespaces the keys of the CachingClient, so that a Store can be shared.
+	// Its version is bumped whenever the cached values change, so that persisted entries lacking fields are not served
+	cacheKeyPrefix = "pokeapi:pokemon:v5:"
+
+	// speciesCacheKeyPrefix namespaces the cached species
+	speciesCacheKeyPrefix = "pokeapi:species:v1:"
+
+	// evolutionCacheKeyPrefix namespaces the cached evolution chains
+	evolutionCacheKeyPrefix = "pokeapi:evolution:v2:"
+
+	// descriptionsCacheKeyPrefix namespaces the cached descriptions
+	descriptionsCacheKeyPrefix = "pokeapi:descriptions:v1:"
+
+	// speciesListCacheKeyPrefix namespaces the cached pages of the species list
+	speciesListCacheKeyPrefix = "pokeapi:species-list:v2:"
+
+	// speciesFilterCacheKeyPrefix namespaces the cached species of the filter categories
+	speciesFilterCacheKeyPrefix = "pokeapi:species-filter:v1:"
+)
+
+// CacheOptions configures a CachingClient, zero values are replaced by the defaults
+type CacheOptions struct {
+	// TTL is the lifetime of a successful lookup
+	TTL time.Duration
+
+	// NotFoundTTL is the lifetime of a lookup that returned ErrPokemonNotFound
+	NotFoundTTL time.Duration
+}
+
+// CachingClient is a Client keeping the results of another Client in a cache.Store.
+// Besides successful lookups, ErrPokemonNotFound results are cached too, every other error is not.
+type CachingClient struct {
+	logger      *log.Logger
+	next        Client
+	store       cache.Store
+	ttl         time.Duration
+	notFoundTTL time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// cachedResult is the outcome of a call to the decorated client, a nil value means not found
+type cachedResult[T any] struct {
+	Value *T `json:"value"`
+}
+
+// NewCachingClient returns a CachingClient decorating next
+func NewCachingClient(logger *log.Logger, next Client, store cache.Store, opts CacheOptions) *CachingClient {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultCacheTTL
+	}
+	if opts.NotFoundTTL <= 0 {
+		opts.NotFoundTTL = DefaultCacheNotFoundTTL
+	}
+	return &CachingClient{
+		logger:      logger,
+		next:        next,
+		store:       store,
+		ttl:         opts.TTL,
+		notFoundTTL: opts.NotFoundTTL,
+	}
+}
+
+func (c *CachingClient) PokemonByName(ctx context.Context, name string, opts ...LookupOption) (*types.Pokemon, error) {
+	return cachedCall(c, pokemonCacheKey(name, NewLookupOptions(opts...)), func() (*types.Pokemon, error) {
+		return c.next.PokemonByName(ctx, name, opts...)
+	})
+}
+
+func (c *CachingClient) Species(ctx context.Context, name string) (*types.Pokemon, error) {
+	return cachedCall(c, speciesCacheKeyPrefix+name, func() (*types.Pokemon, error) {
+		return c.next.Species(ctx, name)
+	})
+}
+
+func (c *CachingClient) ListSpecies(ctx context.Context, offset, limit int) (*SpeciesPage, error) {
+	key := fmt.Sprintf("%soffset=%d&limit=%d", speciesListCacheKeyPrefix, offset, limit)
+	return cachedCall(c, key, func() (*SpeciesPage, error) {
+		return c.next.ListSpecies(ctx, offset, limit)
+	})
+}
+
+func (c *CachingClient) ListSpeciesBy(ctx context.Context, filter SpeciesFilter, value string) ([]SpeciesRef, error) {
+	key := fmt.Sprintf("%s%s/%s", speciesFilterCacheKeyPrefix, filter, value)
+	species, err := cachedCall(c, key, func() (*[]SpeciesRef, error) {
+		species, err := c.next.ListSpeciesBy(ctx, filter, value)
+		return &species, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *species, nil
+}
+
+func (c *CachingClient) Descriptions(ctx context.Context, name string) (types.Descriptions, error) {
+	descriptions, err := cachedCall(c, descriptionsCacheKeyPrefix+name, func() (*types.Descriptions, error) {
+		descriptions, err := c.next.Descriptions(ctx, name)
+		return &descriptions, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return *descriptions, nil
+}
+
+func (c *CachingClient) EvolutionChain(ctx context.Context, name string) (*types.EvolutionNode, error) {
+	return cachedCall(c, evolutionCacheKeyPrefix+name, func() (*types.EvolutionNode, error) {
+		return c.next.EvolutionChain(ctx, name)
+	})
+}
+
+// cachedCall returns the value cached at key, or else the one returned by call, caching it
+func cachedCall[T any](c *CachingClient, key string, call func() (*T, error)) (*T, error) {
+	var result cachedResult[T]
+	if c.get(key, &result) {
+		c.hits.Add(1)
+		if result.Value == nil {
+			return nil, ErrPokemonNotFound
+		}
+		return result.Value, nil
+	}
+	c.misses.Add(1)
+
+	value, err := call()
+	if errors.Is(err, ErrPokemonNotFound) {
+		c.set(key, cachedResult[T]{}, c.notFoundTTL)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(key, cachedResult[T]{value}, c.ttl)
+	return value, nil
+}
+
+// Stats returns the hit and miss counters of the cache,
+// along with the entries and evictions of the store if it reports them
+func (c *CachingClient) Stats() cache.Stats {
+	stats := cache.StoreStats(c.store)
+	stats.Hits = c.hits.Load()
+	stats.Misses = c.misses.Load()
+	return stats
+}
+
+// pokemonCacheKey identifies a PokemonByName lookup, the options selecting different results are part of it
+func pokemonCacheKey(name string, opts LookupOptions) string {
+	key := cacheKeyPrefix + name
+	query := url.Values{}
+	if len(opts.Languages) > 0 {
+		query.Set("lang", strings.ToLower(strings.Join(opts.Languages, ",")))
+	}
+	if opts.Version != "" {
+		query.Set("version", opts.Version)
+	}
+	if len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+	return key
+}
+
+// get decodes the lookup stored at key into lookup, store failures are logged and treated as misses.
+// Every call decodes a new value, so callers can freely modify it.
+func (c *CachingClient) get(key string, lookup any) bool {
+	lookupBytes, ok, err := c.store.Get(key)
+	if err != nil {
+		c.logger.Printf("error reading %s from cache: %v", key, err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+	if err := json.Unmarshal(lookupBytes, lookup); err != nil {
+		c.logger.Printf("error decoding %s from cache: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// set writes a lookup to the store, store failures are logged since the lookup is still valid
+func (c *CachingClient) set(key string, lookup any, ttl time.Duration) {
+	lookupBytes, err := json.Marshal(lookup)
+	if err != nil {
+		c.logger.Printf("error encoding %s for cache: %v", key, err)
+		return
+	}
+	if err := c.store.Set(key, lookupBytes, ttl); err != nil {
+		c.logger.Printf("error writing %s to cache: %v", key, err)
+	}
+}
@@ -0,0 +1,287 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"malta895/pokedex/cache"
+	"malta895/pokedex/types"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type countingClient struct {
+	mockResp         *types.Pokemon
+	mockChain        *types.EvolutionNode
+	mockDescriptions types.Descriptions
+	mockPage         *SpeciesPage
+	mockSpecies      []SpeciesRef
+	mockErr          error
+	calls            int
+}
+
+func (cc *countingClient) PokemonByName(ctx context.Context, name string, opts ...LookupOption) (*types.Pokemon, error) {
+	cc.calls++
+	if cc.mockResp == nil {
+		return nil, cc.mockErr
+	}
+	pokemon := *cc.mockResp
+	return &pokemon, cc.mockErr
+}
+
+func (cc *countingClient) Species(ctx context.Context, name string) (*types.Pokemon, error) {
+	return cc.PokemonByName(ctx, name)
+}
+
+func (cc *countingClient) EvolutionChain(ctx context.Context, name string) (*types.EvolutionNode, error) {
+	cc.calls++
+	return cc.mockChain, cc.mockErr
+}
+
+func (cc *countingClient) Descriptions(ctx context.Context, name string) (types.Descriptions, error) {
+	cc.calls++
+	return cc.mockDescriptions, cc.mockErr
+}
+
+func (cc *countingClient) ListSpecies(ctx context.Context, offset, limit int) (*SpeciesPage, error) {
+	cc.calls++
+	return cc.mockPage, cc.mockErr
+}
+
+func (cc *countingClient) ListSpeciesBy(ctx context.Context, filter SpeciesFilter, value string) ([]SpeciesRef, error) {
+	cc.calls++
+	return cc.mockSpecies, cc.mockErr
+}
+
+func TestCachingClient(t *testing.T) {
+	tests := map[string]struct {
+		next *countingClient
+
+		expectedPokemon *types.Pokemon
+		expectedError   error
+		expectedCalls   int
+		expectedStats   cache.Stats
+	}{
+		"should call the decorated client once for a found pokemon": {
+			next: &countingClient{
+				mockResp: &types.Pokemon{Name: "pikachu", Habitat: "forest"},
+			},
+
+			expectedPokemon: &types.Pokemon{Name: "pikachu", Habitat: "forest"},
+			expectedCalls:   1,
+			expectedStats:   cache.Stats{Hits: 2, Misses: 1, Entries: 1},
+		},
+		"should cache the pokemon not found error": {
+			next: &countingClient{
+				mockErr: ErrPokemonNotFound,
+			},
+
+			expectedError: ErrPokemonNotFound,
+			expectedCalls: 1,
+			expectedStats: cache.Stats{Hits: 2, Misses: 1, Entries: 1},
+		},
+		"should not cache unknown errors": {
+			next: &countingClient{
+				mockErr: ErrUnknown,
+			},
+
+			expectedError: ErrUnknown,
+			expectedCalls: 3,
+			expectedStats: cache.Stats{Misses: 3},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			cachingClient := NewCachingClient(log.Default(), tt.next, cache.NewMemoryStore(10), CacheOptions{})
+
+			for i := 0; i < 3; i++ {
+				found, err := cachingClient.PokemonByName(context.Background(), "pikachu")
+				if !errors.Is(err, tt.expectedError) {
+					t.Errorf("received error %v; want %v", err, tt.expectedError)
+				}
+				if !reflect.DeepEqual(found, tt.expectedPokemon) {
+					t.Errorf("PokemonByName(pikachu) = %#v; want %#v", found, tt.expectedPokemon)
+				}
+			}
+
+			if tt.next.calls != tt.expectedCalls {
+				t.Errorf("found %d calls to the decorated client; want %d", tt.next.calls, tt.expectedCalls)
+			}
+			if found := cachingClient.Stats(); found != tt.expectedStats {
+				t.Errorf("found stats %+v; want %+v", found, tt.expectedStats)
+			}
+		})
+	}
+
+	t.Run("should not let callers modify the cached pokemon", func(t *testing.T) {
+		cachingClient := NewCachingClient(log.Default(), &countingClient{
+			mockResp: &types.Pokemon{Name: "pikachu", Description: "original"},
+		}, cache.NewMemoryStore(10), CacheOptions{})
+
+		first, _ := cachingClient.PokemonByName(context.Background(), "pikachu")
+		first.Description = "translated"
+		second, _ := cachingClient.PokemonByName(context.Background(), "pikachu")
+		second.Description = "translated again"
+		third, _ := cachingClient.PokemonByName(context.Background(), "pikachu")
+
+		if third.Description != "original" {
+			t.Errorf("found description %s; want original", third.Description)
+		}
+	})
+}
+
+func TestCachingClientLookupOptions(t *testing.T) {
+	t.Run("should cache the lookups with different options separately", func(t *testing.T) {
+		next := &countingClient{mockResp: &types.Pokemon{Name: "pikachu"}}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(10), CacheOptions{})
+
+		lookups := [][]LookupOption{
+			nil,
+			{WithLanguages("it")},
+			{WithLanguages("it", "de")},
+			{WithLanguages("IT")},
+			{WithLanguages("it")},
+			{WithVersion("sword")},
+			{WithLanguages("it"), WithVersion("sword")},
+			{WithVersion("sword"), WithLanguages("it")},
+			nil,
+		}
+		for _, opts := range lookups {
+			if _, err := cachingClient.PokemonByName(context.Background(), "pikachu", opts...); err != nil {
+				t.Errorf("received error %v; want nil", err)
+			}
+		}
+		if next.calls != 5 {
+			t.Errorf("found %d calls to the decorated client; want 5", next.calls)
+		}
+	})
+}
+
+func TestCachingClientDescriptions(t *testing.T) {
+	t.Run("should call the decorated client once for the descriptions", func(t *testing.T) {
+		descriptions := types.Descriptions{"red": {"en": "red text"}}
+		next := &countingClient{mockDescriptions: descriptions}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(10), CacheOptions{})
+
+		for i := 0; i < 3; i++ {
+			found, err := cachingClient.Descriptions(context.Background(), "pikachu")
+			if err != nil {
+				t.Errorf("received error %v; want nil", err)
+			}
+			if !reflect.DeepEqual(found, descriptions) {
+				t.Errorf("Descriptions(pikachu) = %#v; want %#v", found, descriptions)
+			}
+		}
+		if next.calls != 1 {
+			t.Errorf("found %d calls to the decorated client; want 1", next.calls)
+		}
+	})
+}
+
+func TestCachingClientEvolutionChain(t *testing.T) {
+	t.Run("should call the decorated client once for an evolution chain", func(t *testing.T) {
+		chain := &types.EvolutionNode{
+			Species: "pichu",
+			EvolvesTo: []types.EvolutionNode{{
+				Species:   "pikachu",
+				Triggers:  []types.EvolutionTrigger{{Trigger: "level-up", MinHappiness: 220}},
+				EvolvesTo: []types.EvolutionNode{},
+			}},
+		}
+		next := &countingClient{mockChain: chain}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(10), CacheOptions{})
+
+		for i := 0; i < 3; i++ {
+			found, err := cachingClient.EvolutionChain(context.Background(), "pikachu")
+			if err != nil {
+				t.Errorf("received error %v; want nil", err)
+			}
+			if !reflect.DeepEqual(found, chain) {
+				t.Errorf("EvolutionChain(pikachu) = %#v; want %#v", found, chain)
+			}
+		}
+		if next.calls != 1 {
+			t.Errorf("found %d calls to the decorated client; want 1", next.calls)
+		}
+	})
+
+	t.Run("should cache the pokemon not found error", func(t *testing.T) {
+		next := &countingClient{mockErr: ErrPokemonNotFound}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(10), CacheOptions{})
+
+		for i := 0; i < 3; i++ {
+			if _, err := cachingClient.EvolutionChain(context.Background(), "missingno"); !errors.Is(err, ErrPokemonNotFound) {
+				t.Errorf("received error %v; want %v", err, ErrPokemonNotFound)
+			}
+		}
+		if next.calls != 1 {
+			t.Errorf("found %d calls to the decorated client; want 1", next.calls)
+		}
+	})
+}
+
+func TestCachingClientExpiration(t *testing.T) {
+	t.Run("should cache pokemon not found errors for the not found ttl", func(t *testing.T) {
+		store := cache.NewMemoryStore(10)
+		cachingClient := NewCachingClient(log.Default(), &countingClient{
+			mockErr: ErrPokemonNotFound,
+		}, store, CacheOptions{TTL: time.Hour, NotFoundTTL: time.Minute})
+
+		cachingClient.PokemonByName(context.Background(), "missingno")
+
+		ttl, ok, _ := store.TTL(cacheKeyPrefix + "missingno")
+		if !ok || ttl > time.Minute {
+			t.Errorf("found ttl %s, %v; want at most %s", ttl, ok, time.Minute)
+		}
+	})
+}
+
+func TestCachingClientListSpecies(t *testing.T) {
+	t.Run("should call the decorated client once per page", func(t *testing.T) {
+		page := &SpeciesPage{Count: 2, Species: []SpeciesRef{{1, "bulbasaur"}, {2, "ivysaur"}}}
+		next := &countingClient{mockPage: page}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(10), CacheOptions{})
+
+		for i := 0; i < 3; i++ {
+			found, err := cachingClient.ListSpecies(context.Background(), 0, 2)
+			if err != nil {
+				t.Errorf("received error %v; want nil", err)
+			}
+			if !reflect.DeepEqual(found, page) {
+				t.Errorf("ListSpecies(0, 2) = %#v; want %#v", found, page)
+			}
+		}
+		if _, err := cachingClient.ListSpecies(context.Background(), 2, 2); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if next.calls != 2 {
+			t.Errorf("found %d calls to the decorated client; want 2", next.calls)
+		}
+	})
+}
+
+func TestCachingClientListSpeciesBy(t *testing.T) {
+	t.Run("should call the decorated client once per filter value", func(t *testing.T) {
+		species := []SpeciesRef{{41, "zubat"}, {74, "geodude"}}
+		next := &countingClient{mockSpecies: species}
+		cachingClient := NewCachingClient(log.Default(), next, cache.NewMemoryStore(10), CacheOptions{})
+
+		for i := 0; i < 3; i++ {
+			found, err := cachingClient.ListSpeciesBy(context.Background(), FilterHabitat, "cave")
+			if err != nil {
+				t.Errorf("received error %v; want nil", err)
+			}
+			if !reflect.DeepEqual(found, species) {
+				t.Errorf("ListSpeciesBy(habitat, cave) = %#v; want %#v", found, species)
+			}
+		}
+		if _, err := cachingClient.ListSpeciesBy(context.Background(), FilterColor, "cave"); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if next.calls != 2 {
+			t.Errorf("found %d calls to the decorated client; want 2", next.calls)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"malta895/pokedex/apiclients/circuitbreaker"
+	"malta895/pokedex/types"
+)
+
+type circuitBreakerClient struct {
+	next    Client
+	breaker *circuitbreaker.Breaker
+}
+
+// NewCircuitBreakerClient returns a Client failing fast with circuitbreaker.ErrOpen
+// while next keeps failing. Pokemon or filters not found and cancelled calls are not failures.
+func NewCircuitBreakerClient(next Client, breaker *circuitbreaker.Breaker) Client {
+	return &circuitBreakerClient{next, breaker}
+}
+
+func (c *circuitBreakerClient) PokemonByName(ctx context.Context, name string, opts ...LookupOption) (*types.Pokemon, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	pokemon, err := c.next.PokemonByName(ctx, name, opts...)
+	done(isFailure(err))
+	return pokemon, err
+}
+
+func (c *circuitBreakerClient) Species(ctx context.Context, name string) (*types.Pokemon, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	pokemon, err := c.next.Species(ctx, name)
+	done(isFailure(err))
+	return pokemon, err
+}
+
+func (c *circuitBreakerClient) ListSpecies(ctx context.Context, offset, limit int) (*SpeciesPage, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	page, err := c.next.ListSpecies(ctx, offset, limit)
+	done(isFailure(err))
+	return page, err
+}
+
+func (c *circuitBreakerClient) ListSpeciesBy(ctx context.Context, filter SpeciesFilter, value string) ([]SpeciesRef, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	species, err := c.next.ListSpeciesBy(ctx, filter, value)
+	done(isFailure(err))
+	return species, err
+}
+
+func (c *circuitBreakerClient) Descriptions(ctx context.Context, name string) (types.Descriptions, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	descriptions, err := c.next.Descriptions(ctx, name)
+	done(isFailure(err))
+	return descriptions, err
+}
+
+func (c *circuitBreakerClient) EvolutionChain(ctx context.Context, name string) (*types.EvolutionNode, error) {
+	done, err := c.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	chain, err := c.next.EvolutionChain(ctx, name)
+	done(isFailure(err))
+	return chain, err
+}
+
+// isFailure reports whether err says the PokeAPI is unhealthy
+func isFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ErrPokemonNotFound) &&
+		!errors.Is(err, ErrFilterNotFound) &&
+		!errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,60 @@
+package pokeapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"malta895/pokedex/apiclients/circuitbreaker"
+	"testing"
+)
+
+func TestCircuitBreakerClient(t *testing.T) {
+	tests := map[string]struct {
+		mockErr error
+
+		expectedCalls int
+		expectedState string
+	}{
+		"should fail fast after consecutive unknown errors": {
+			mockErr: ErrUnknown,
+
+			expectedCalls: 2,
+			expectedState: "open",
+		},
+		"should not count pokemon not found as a failure": {
+			mockErr: ErrPokemonNotFound,
+
+			expectedCalls: 3,
+			expectedState: "closed",
+		},
+		"should not count filter not found as a failure": {
+			mockErr: ErrFilterNotFound,
+
+			expectedCalls: 3,
+			expectedState: "closed",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			next := &countingClient{mockErr: tt.mockErr}
+			breaker := circuitbreaker.New("pokeapi", log.Default(), circuitbreaker.Settings{FailureThreshold: 2})
+			breakerClient := NewCircuitBreakerClient(next, breaker)
+
+			var err error
+			for i := 0; i < 3; i++ {
+				_, err = breakerClient.PokemonByName(context.Background(), "pikachu")
+			}
+
+			if next.calls != tt.expectedCalls {
+				t.Errorf("found %d calls to the decorated client; want %d", next.calls, tt.expectedCalls)
+			}
+			if found := breaker.Status().State; found != tt.expectedState {
+				t.Errorf("found state %s; want %s", found, tt.expectedState)
+			}
+			if tt.expectedState == "open" && !errors.Is(err, circuitbreaker.ErrOpen) {
+				t.Errorf("received error %v; want %v", err, circuitbreaker.ErrOpen)
+			}
+		})
+	}
+}
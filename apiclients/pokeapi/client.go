@@ -1,21 +1,34 @@
 package pokeapi
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/apiclients/retry"
 	"malta895/pokedex/types"
 	"net/http"
 	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 )
 
 var (
 	ErrPokemonNotFound = errors.New("pokemon not found")
+	ErrFilterNotFound  = errors.New("species filter value not found")
 	ErrUnknown         = errors.New("cannot retrieve pokemon due to unknown error")
 )
 
 const (
+	// serviceName identifies the PokeAPI in the errors returned by the client
+	serviceName = "pokeapi"
+
 	// pokeAPIBaseURL is the base URL of the `pokeapi.co` v2 APIs
 	pokeAPIBaseURL = "https://pokeapi.co/api/v2"
 
@@ -23,52 +36,298 @@ const (
 	//
 	// Reference: https://pokeapi.co/docs/v2#pokemon-species
 	pokemonSpeciesPath = "/pokemon-species"
+
+	// pokemonPath is the complete URL of the pokemon pokeapi endpoint
+	//
+	// Reference: https://pokeapi.co/docs/v2#pokemon
+	pokemonPath = "/pokemon"
+
+	// evolutionChainPath is the complete URL of the evolution-chain pokeapi endpoint
+	//
+	// Reference: https://pokeapi.co/docs/v2#evolution-chains
+	evolutionChainPath = "/evolution-chain"
+
+	// versionGroupPath is the complete URL of the version-group pokeapi endpoint
+	//
+	// Reference: https://pokeapi.co/docs/v2#version-groups
+	versionGroupPath = "/version-group"
 )
 
 type client struct {
-	baseURL string
+	baseURL     string
+	httpClient  *http.Client
+	userAgent   string
+	headers     http.Header
+	retryPolicy retry.Policy
+	logger      *log.Logger
 }
 
 type Client interface {
-	PokemonByName(name string) (*types.Pokemon, error)
+	// PokemonByName retrieves the pokemon with the given species name.
+	// The description is in the most preferred available language set with WithLanguages, or else in English,
+	// and it is taken from the game version set with WithVersion when available.
+	// The upstream call is aborted as soon as ctx is done.
+	PokemonByName(ctx context.Context, name string, opts ...LookupOption) (*types.Pokemon, error)
+
+	// Species retrieves the species with the given name, i.e. the fields of PokemonByName but the battle data,
+	// with the description in English. It costs a single upstream call, unlike PokemonByName.
+	// The upstream call is aborted as soon as ctx is done.
+	Species(ctx context.Context, name string) (*types.Pokemon, error)
+
+	// ListSpecies retrieves a page of the names of every species, in National Pokédex order.
+	// The upstream call is aborted as soon as ctx is done.
+	ListSpecies(ctx context.Context, offset, limit int) (*SpeciesPage, error)
+
+	// ListSpeciesBy retrieves the species in the category value of filter, e.g. the `cave` habitat,
+	// in National Pokédex order. It returns ErrFilterNotFound if the category does not exist.
+	// The upstream call is aborted as soon as ctx is done.
+	ListSpeciesBy(ctx context.Context, filter SpeciesFilter, value string) ([]SpeciesRef, error)
+
+	// Descriptions retrieves every description of the species with the given name,
+	// keyed by game version and language.
+	// The upstream call is aborted as soon as ctx is done.
+	Descriptions(ctx context.Context, name string) (types.Descriptions, error)
+
+	// EvolutionChain retrieves the whole evolution chain the species with the given name belongs to,
+	// starting from its first species.
+	// The upstream calls are aborted as soon as ctx is done.
+	EvolutionChain(ctx context.Context, name string) (*types.EvolutionNode, error)
+}
+
+// NewClient returns a Client for the PokeAPI,
+// by default it contacts `pokeapi.co` with a 10 seconds timeout
+func NewClient(opts ...httpclient.Option) Client {
+	o := httpclient.NewOptions(pokeAPIBaseURL, opts...)
+	return &client{
+		baseURL:     o.BaseURL,
+		httpClient:  o.BuildHTTPClient(),
+		userAgent:   o.UserAgent,
+		headers:     o.Headers,
+		retryPolicy: o.RetryPolicy,
+		logger:      o.Logger,
+	}
+}
+
+func (p *client) PokemonByName(ctx context.Context, name string, opts ...LookupOption) (*types.Pokemon, error) {
+	lookupOpts := NewLookupOptions(opts...)
+	species := pokemonSpecies{}
+	if err := p.getResource(ctx, &species, pokemonSpeciesPath, name); err != nil {
+		return nil, err
+	}
+
+	// the battle data lives in the pokemon resource of the default variety of the species
+	details := pokemon{}
+	if err := p.getResource(ctx, &details, pokemonPath, defaultVarietyName(species, name)); err != nil {
+		return nil, err
+	}
+
+	versions, err := p.resolveVersions(ctx, species.FlavorTextEntries, lookupOpts.Version)
+	if err != nil {
+		return nil, err
+	}
+	description, _ := selectDescription(species.FlavorTextEntries, lookupOpts.Languages, versions)
+	result := mapSpecies(species, description)
+	mergePokemonDetails(result, details)
+	return result, nil
+}
+
+func (p *client) Species(ctx context.Context, name string) (*types.Pokemon, error) {
+	species := pokemonSpecies{}
+	if err := p.getResource(ctx, &species, pokemonSpeciesPath, name); err != nil {
+		return nil, err
+	}
+	description, _ := selectDescription(species.FlavorTextEntries, nil, nil)
+	return mapSpecies(species, description), nil
+}
+
+func (p *client) ListSpecies(ctx context.Context, offset, limit int) (*SpeciesPage, error) {
+	resURL, err := url.JoinPath(p.baseURL, pokemonSpeciesPath)
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	query.Set("offset", strconv.Itoa(offset))
+	query.Set("limit", strconv.Itoa(limit))
+
+	list := namedAPIResourceList{}
+	listURL := resURL + "?" + query.Encode()
+	if err := p.getURL(ctx, &list, listURL); err != nil {
+		return nil, err
+	}
+
+	page := &SpeciesPage{Count: list.Count, Species: make([]SpeciesRef, 0, len(list.Results))}
+	for _, result := range list.Results {
+		ref, err := speciesRef(result)
+		if err != nil {
+			return nil, malformedResourceError(listURL, err)
+		}
+		page.Species = append(page.Species, ref)
+	}
+	return page, nil
+}
+
+func (p *client) ListSpeciesBy(ctx context.Context, filter SpeciesFilter, value string) ([]SpeciesRef, error) {
+	resURL, err := url.JoinPath(p.baseURL, string(filter), value)
+	if err != nil {
+		return nil, err
+	}
+	category := speciesCategory{}
+	if err := p.getURL(ctx, &category, resURL); err != nil {
+		if errors.Is(err, ErrPokemonNotFound) {
+			return nil, fmt.Errorf("%w: %s %q", ErrFilterNotFound, filter, value)
+		}
+		return nil, err
+	}
+
+	resources := category.PokemonSpecies
+	for _, t := range category.Pokemon {
+		resources = append(resources, t.Pokemon)
+	}
+	species := make([]SpeciesRef, 0, len(resources))
+	for _, resource := range resources {
+		ref, err := speciesRef(resource)
+		if err != nil {
+			return nil, malformedResourceError(resURL, err)
+		}
+		// the types list every variety, only the default ones identify a species
+		if ref.ID >= firstVarietyID {
+			continue
+		}
+		species = append(species, ref)
+	}
+	slices.SortFunc(species, func(a, b SpeciesRef) int { return a.ID - b.ID })
+	return species, nil
+}
+
+func (p *client) Descriptions(ctx context.Context, name string) (types.Descriptions, error) {
+	species := pokemonSpecies{}
+	if err := p.getResource(ctx, &species, pokemonSpeciesPath, name); err != nil {
+		return nil, err
+	}
+
+	descriptions := types.Descriptions{}
+	for _, entry := range species.FlavorTextEntries {
+		byLanguage, ok := descriptions[entry.Version.Name]
+		if !ok {
+			byLanguage = map[string]string{}
+			descriptions[entry.Version.Name] = byLanguage
+		}
+		if _, ok := byLanguage[entry.Language.Name]; !ok {
+			byLanguage[entry.Language.Name] = entry.FlavorText
+		}
+	}
+	return descriptions, nil
 }
 
-func NewClient() Client {
-	return &client{pokeAPIBaseURL}
+// resolveVersions returns the game versions matching version, that is either a version
+// of the entries or a version group; an unknown version group matches no version.
+// Only the versions spelled as a slug are looked up as a version group, the others cannot name one.
+func (p *client) resolveVersions(ctx context.Context, entries []flavorText, version string) ([]string, error) {
+	if version == "" {
+		return nil, nil
+	}
+	if hasVersion(entries, version) || Slug(version) != version {
+		return []string{version}, nil
+	}
+
+	group := versionGroup{}
+	err := p.getResource(ctx, &group, versionGroupPath, version)
+	if errors.Is(err, ErrPokemonNotFound) {
+		return []string{version}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]string, 0, len(group.Versions))
+	for _, v := range group.Versions {
+		versions = append(versions, v.Name)
+	}
+	return versions, nil
 }
 
-func (p *client) PokemonByName(name string) (*types.Pokemon, error) {
-	resURL, err := url.JoinPath(p.baseURL, pokemonSpeciesPath, name)
+func (p *client) EvolutionChain(ctx context.Context, name string) (*types.EvolutionNode, error) {
+	speciesURL, err := url.JoinPath(p.baseURL, pokemonSpeciesPath, name)
 	if err != nil {
 		return nil, err
 	}
+	species := pokemonSpecies{}
+	if err := p.getURL(ctx, &species, speciesURL); err != nil {
+		return nil, err
+	}
 
-	resp, err := http.Get(resURL)
+	// the chain is requested by ID, so that it is fetched from the configured base URL
+	chainID, err := resourceID(species.EvolutionChain.URL)
 	if err != nil {
+		return nil, malformedResourceError(speciesURL, fmt.Errorf("evolution chain of %s: %w", name, err))
+	}
+	chain := evolutionChain{}
+	if err := p.getResource(ctx, &chain, evolutionChainPath, chainID); err != nil {
 		return nil, err
 	}
+
+	root := mapChainLink(chain.Chain)
+	return &root, nil
+}
+
+// getResource retrieves the resource at the given path of the PokeAPI and decodes it into target
+func (p *client) getResource(ctx context.Context, target any, path ...string) error {
+	resURL, err := url.JoinPath(p.baseURL, path...)
+	if err != nil {
+		return err
+	}
+	return p.getURL(ctx, target, resURL)
+}
+
+// getURL retrieves the resource at resURL and decodes it into target
+func (p *client) getURL(ctx context.Context, target any, resURL string) error {
+	req, err := p.newRequest(ctx, http.MethodGet, resURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.retryPolicy.Do(p.logger, p.httpClient, req)
+	if err != nil {
+		return apiclients.NewTransportError(serviceName, req, err)
+	}
 	defer resp.Body.Close()
 
 	if err := mapStatusToErr(resp.StatusCode); err != nil {
-		return nil, err
+		return apiclients.NewStatusError(serviceName, resp, err)
 	}
 
 	respBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrUnknown, err)
+		upstreamErr := apiclients.NewBodyError(serviceName, req.URL.Redacted(), resp.StatusCode, fmt.Errorf("%w: %w", ErrUnknown, err))
+		// the connection failed while reading, unlike a malformed body it may work next time
+		upstreamErr.Retryable = !errors.Is(err, context.Canceled)
+		return upstreamErr
 	}
 
-	pokemonSpecies := pokemonSpecies{}
-	if err := json.Unmarshal(respBytes, &pokemonSpecies); err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrUnknown, err)
+	if err := json.Unmarshal(respBytes, target); err != nil {
+		return apiclients.NewBodyError(serviceName, req.URL.Redacted(), resp.StatusCode, fmt.Errorf("%w: %w", ErrUnknown, err))
 	}
+	return nil
+}
 
-	return &types.Pokemon{
-		Name:        pokemonSpecies.Name,
-		Description: retrieveFirstEnglishDescription(pokemonSpecies),
-		Habitat:     pokemonSpecies.Habitat.Name,
-		IsLegendary: pokemonSpecies.IsLegendary,
-	}, nil
+// malformedResourceError returns the error of a resource retrieved from resURL that does not make sense, because of err
+func malformedResourceError(resURL string, err error) error {
+	return apiclients.NewBodyError(serviceName, resURL, http.StatusOK, fmt.Errorf("%w: %w", ErrUnknown, err))
+}
+
+// newRequest builds a request carrying the configured user agent and headers
+func (p *client) newRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range p.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if p.userAgent != "" {
+		req.Header.Set("User-Agent", p.userAgent)
+	}
+	return req, nil
 }
 
 func mapStatusToErr(statusCode int) error {
@@ -81,11 +340,95 @@ func mapStatusToErr(statusCode int) error {
 	return nil
 }
 
-func retrieveFirstEnglishDescription(ps pokemonSpecies) string {
-	for _, flavorTextEntry := range ps.FlavorTextEntries {
-		if flavorTextEntry.Language.Name == "en" {
-			return flavorTextEntry.FlavorText
+// defaultVarietyName returns the name of the pokemon resource of the default variety of the species,
+// falling back to the species name, e.g. "deoxys" has "deoxys-normal" as default variety
+func defaultVarietyName(ps pokemonSpecies, fallback string) string {
+	for _, v := range ps.Varieties {
+		if v.IsDefault && v.Pokemon.Name != "" {
+			return v.Pokemon.Name
+		}
+	}
+	if ps.Name != "" {
+		return ps.Name
+	}
+	return fallback
+}
+
+// mapSpecies returns the pokemon described by species, with the given description
+func mapSpecies(species pokemonSpecies, description flavorText) *types.Pokemon {
+	return &types.Pokemon{
+		Name:        species.Name,
+		Description: description.FlavorText,
+		Language:    description.Language.Name,
+		Version:     description.Version.Name,
+		Habitat:     species.Habitat.Name,
+		IsLegendary: species.IsLegendary,
+		IsMythical:  species.IsMythical,
+		Generation:  species.Generation.Name,
+	}
+}
+
+// mergePokemonDetails copies the measures, types, abilities and stats of the pokemon resource into result
+func mergePokemonDetails(result *types.Pokemon, details pokemon) {
+	result.Height = details.Height
+	result.Weight = details.Weight
+	result.BaseExperience = details.BaseExperience
+
+	pokemonTypes := slices.Clone(details.Types)
+	slices.SortStableFunc(pokemonTypes, func(a, b pokemonType) int { return a.Slot - b.Slot })
+	for _, t := range pokemonTypes {
+		result.Types = append(result.Types, t.Type.Name)
+	}
+
+	abilities := slices.Clone(details.Abilities)
+	slices.SortStableFunc(abilities, func(a, b pokemonAbility) int { return a.Slot - b.Slot })
+	for _, a := range abilities {
+		result.Abilities = append(result.Abilities, types.Ability{Name: a.Ability.Name, IsHidden: a.IsHidden})
+	}
+
+	for _, s := range details.Stats {
+		if result.BaseStats == nil {
+			result.BaseStats = make(map[string]int, len(details.Stats))
 		}
+		result.BaseStats[s.Stat.Name] = s.BaseStat
+	}
+}
+
+// resourceID extracts the ID from the URL of a pokeapi resource, e.g. "67" from
+// "https://pokeapi.co/api/v2/evolution-chain/67/"
+func resourceID(resourceURL string) (string, error) {
+	u, err := url.Parse(resourceURL)
+	if err != nil {
+		return "", err
+	}
+	id := path.Base(strings.TrimSuffix(u.Path, "/"))
+	if id == "." || id == "/" {
+		return "", fmt.Errorf("no resource ID in URL %q", resourceURL)
+	}
+	return id, nil
+}
+
+func mapChainLink(link chainLink) types.EvolutionNode {
+	node := types.EvolutionNode{
+		Species:   link.Species.Name,
+		EvolvesTo: make([]types.EvolutionNode, 0, len(link.EvolvesTo)),
+	}
+	for _, detail := range link.EvolutionDetails {
+		node.Triggers = append(node.Triggers, types.EvolutionTrigger{
+			Trigger:       detail.Trigger.Name,
+			MinLevel:      detail.MinLevel,
+			Item:          detail.Item.Name,
+			HeldItem:      detail.HeldItem.Name,
+			TradeSpecies:  detail.TradeSpecies.Name,
+			MinHappiness:  detail.MinHappiness,
+			MinAffection:  detail.MinAffection,
+			TimeOfDay:     detail.TimeOfDay,
+			KnownMoveType: detail.KnownMoveType.Name,
+			Location:      detail.Location.Name,
+		})
+	}
+	for _, next := range link.EvolvesTo {
+		node.EvolvesTo = append(node.EvolvesTo, mapChainLink(next))
 	}
-	return ""
+	return node
 }
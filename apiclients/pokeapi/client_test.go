@@ -1,18 +1,27 @@
 package pokeapi
 
 import (
+	"context"
+	"errors"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/apiclients/retry"
 	"malta895/pokedex/types"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestPokemonByName(t *testing.T) {
 	tests := map[string]struct {
 		pokemonName         string
+		lookupOpts          []LookupOption
 		mockPokeAPIResponse string
 		nonOKStatusCode     int
+		varietyName         string
+		mockPokemonResponse string
 
 		expectedPokemon *types.Pokemon
 		expectedError   error
@@ -40,12 +49,36 @@ func TestPokemonByName(t *testing.T) {
 			expectedPokemon: &types.Pokemon{
 				Name:        "fakelegend",
 				Description: "This is a mock legendary pokemon",
+				Language:    "en",
 				Habitat:     "mockHabitat",
 				IsLegendary: true,
 			},
 			expectedError:   nil,
 			expectAPICalled: true,
 		},
+		"should respond with the mythical flag and the generation": {
+			pokemonName: "fakemyth",
+			mockPokeAPIResponse: `{
+				"flavor_text_entries": [
+					{"flavor_text": "This is a mock mythical pokemon", "language": {"name": "en"}}
+				],
+				"habitat": {"name": "rare"},
+				"is_legendary": false,
+				"is_mythical": true,
+				"generation": {"name": "generation-i", "url": "https://pokeapi.co/api/v2/generation/1/"},
+				"name": "fakemyth"
+			}`,
+
+			expectedPokemon: &types.Pokemon{
+				Name:        "fakemyth",
+				Description: "This is a mock mythical pokemon",
+				Language:    "en",
+				Habitat:     "rare",
+				IsMythical:  true,
+				Generation:  "generation-i",
+			},
+			expectAPICalled: true,
+		},
 		"should respond with the first english description": {
 			pokemonName: "bigpokemon",
 			mockPokeAPIResponse: `{
@@ -80,12 +113,143 @@ func TestPokemonByName(t *testing.T) {
 			expectedPokemon: &types.Pokemon{
 				Name:        "bigpokemon",
 				Description: "This is a mock big pokemon",
+				Language:    "en",
 				Habitat:     "mockHabitat",
 				IsLegendary: false,
 			},
 			expectedError:   nil,
 			expectAPICalled: true,
 		},
+		"should respond with the description in the most preferred available language": {
+			pokemonName: "bigpokemon",
+			lookupOpts:  []LookupOption{WithLanguages("fr", "it-IT", "de")},
+			mockPokeAPIResponse: `{
+				"flavor_text_entries": [
+				  {"flavor_text": "This is a mock big pokemon", "language": {"name": "en"}},
+				  {"flavor_text": "Das ist ein großes Testpokemon", "language": {"name": "de"}},
+				  {"flavor_text": "Questo è un pokemon grande di test", "language": {"name": "it"}}
+				],
+				"habitat": {"name": "mockHabitat"},
+				"name": "bigpokemon"
+			  }
+			`,
+
+			expectedPokemon: &types.Pokemon{
+				Name:        "bigpokemon",
+				Description: "Questo è un pokemon grande di test",
+				Language:    "it",
+				Habitat:     "mockHabitat",
+			},
+			expectedError:   nil,
+			expectAPICalled: true,
+		},
+		"should fall back to the english description": {
+			pokemonName: "bigpokemon",
+			lookupOpts:  []LookupOption{WithLanguages("ko")},
+			mockPokeAPIResponse: `{
+				"flavor_text_entries": [
+				  {"flavor_text": "Questo è un pokemon grande di test", "language": {"name": "it"}},
+				  {"flavor_text": "This is a mock big pokemon", "language": {"name": "en"}}
+				],
+				"habitat": {"name": "mockHabitat"},
+				"name": "bigpokemon"
+			  }
+			`,
+
+			expectedPokemon: &types.Pokemon{
+				Name:        "bigpokemon",
+				Description: "This is a mock big pokemon",
+				Language:    "en",
+				Habitat:     "mockHabitat",
+			},
+			expectedError:   nil,
+			expectAPICalled: true,
+		},
+		"should match a language by its primary subtag": {
+			pokemonName: "bigpokemon",
+			lookupOpts:  []LookupOption{WithLanguages("ja")},
+			mockPokeAPIResponse: `{
+				"flavor_text_entries": [
+				  {"flavor_text": "This is a mock big pokemon", "language": {"name": "en"}},
+				  {"flavor_text": "テストの大きいポケモン", "language": {"name": "ja-Hrkt"}}
+				],
+				"habitat": {"name": "mockHabitat"},
+				"name": "bigpokemon"
+			  }
+			`,
+
+			expectedPokemon: &types.Pokemon{
+				Name:        "bigpokemon",
+				Description: "テストの大きいポケモン",
+				Language:    "ja-Hrkt",
+				Habitat:     "mockHabitat",
+			},
+			expectedError:   nil,
+			expectAPICalled: true,
+		},
+		"should merge the battle data of the default variety": {
+			pokemonName: "fakeform",
+			mockPokeAPIResponse: `{
+				"flavor_text_entries": [],
+				"habitat": {
+				  "name": "mockHabitat"
+				},
+				"is_legendary": false,
+				"name": "fakeform",
+				"varieties": [
+				  {
+					"is_default": false,
+					"pokemon": {
+					  "name": "fakeform-alternate"
+					}
+				  },
+				  {
+					"is_default": true,
+					"pokemon": {
+					  "name": "fakeform-normal"
+					}
+				  }
+				]
+			  }
+			`,
+			varietyName: "fakeform-normal",
+			mockPokemonResponse: `{
+				"name": "fakeform-normal",
+				"base_experience": 112,
+				"height": 4,
+				"weight": 60,
+				"abilities": [
+				  {"ability": {"name": "lightning-rod"}, "is_hidden": true, "slot": 3},
+				  {"ability": {"name": "static"}, "is_hidden": false, "slot": 1}
+				],
+				"stats": [
+				  {"base_stat": 35, "stat": {"name": "hp"}},
+				  {"base_stat": 90, "stat": {"name": "speed"}}
+				],
+				"types": [
+				  {"slot": 2, "type": {"name": "flying"}},
+				  {"slot": 1, "type": {"name": "electric"}}
+				]
+			  }
+			`,
+
+			expectedPokemon: &types.Pokemon{
+				Name:        "fakeform",
+				Habitat:     "mockHabitat",
+				IsLegendary: false,
+				Types:       []string{"electric", "flying"},
+				Abilities: []types.Ability{
+					{Name: "static", IsHidden: false},
+					{Name: "lightning-rod", IsHidden: true},
+				},
+				BaseStats:      map[string]int{"hp": 35, "speed": 90},
+				Height:         4,
+				Weight:         60,
+				BaseExperience: 112,
+			},
+			expectedError:   nil,
+			expectAPICalled: true,
+		},
 		"should respond with the pokemon not found error if the api responds 404": {
 			pokemonName:         "nonexisting",
 			mockPokeAPIResponse: `Not Found`,
@@ -113,20 +277,30 @@ func TestPokemonByName(t *testing.T) {
 			if tt.nonOKStatusCode != 0 {
 				statusCode = tt.nonOKStatusCode
 			}
-			server := mockPokeAPIServer(
+			varietyName := tt.pokemonName
+			if tt.varietyName != "" {
+				varietyName = tt.varietyName
+			}
+			mockPokemonResponse := `{}`
+			if tt.mockPokemonResponse != "" {
+				mockPokemonResponse = tt.mockPokemonResponse
+			}
+			server := mockPokeAPIServerWithDetails(
 				t,
 				tt.pokemonName,
 				tt.mockPokeAPIResponse,
 				statusCode,
+				varietyName,
+				mockPokemonResponse,
 				func() {
 					apiCalled = true
 				},
 			)
 			defer server.Close()
-			pokemonClient := &client{server.URL}
+			pokemonClient := NewClient(httpclient.WithBaseURL(server.URL))
 
-			foundResp, err := pokemonClient.PokemonByName(tt.pokemonName)
-			if err != tt.expectedError {
+			foundResp, err := pokemonClient.PokemonByName(context.Background(), tt.pokemonName, tt.lookupOpts...)
+			if !errors.Is(err, tt.expectedError) {
 				t.Errorf(
 					"received error %v; want %v",
 					err,
@@ -148,6 +322,412 @@ func TestPokemonByName(t *testing.T) {
 	}
 }
 
+func TestPokemonByNameContextCancellation(t *testing.T) {
+	t.Run("should abort the upstream call when the context is cancelled", func(t *testing.T) {
+		unblock := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-unblock
+		}))
+		defer server.Close()
+		defer close(unblock)
+		pokemonClient := NewClient(httpclient.WithBaseURL(server.URL))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		foundResp, err := pokemonClient.PokemonByName(ctx, "slowpoke")
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("received error %v; want %v", err, context.DeadlineExceeded)
+		}
+		if foundResp != nil {
+			t.Errorf("PokemonByName(slowpoke) = %#v; want nil", foundResp)
+		}
+	})
+}
+
+func TestPokemonByNameVersion(t *testing.T) {
+	speciesResp := `{
+		"name": "pikachu",
+		"flavor_text_entries": [
+			{"flavor_text": "red text", "language": {"name": "en"}, "version": {"name": "red"}},
+			{"flavor_text": "sword text", "language": {"name": "en"}, "version": {"name": "sword"}},
+			{"flavor_text": "testo shield", "language": {"name": "it"}, "version": {"name": "shield"}},
+			{"flavor_text": "shield text", "language": {"name": "en"}, "version": {"name": "shield"}}
+		]
+	}`
+	tests := map[string]struct {
+		lookupOpts []LookupOption
+
+		expectedDescription string
+		expectedLanguage    string
+		expectedVersion     string
+	}{
+		"should take the first description without a version": {
+			expectedDescription: "red text",
+			expectedLanguage:    "en",
+			expectedVersion:     "red",
+		},
+		"should take the description of the given version": {
+			lookupOpts: []LookupOption{WithVersion("sword")},
+
+			expectedDescription: "sword text",
+			expectedLanguage:    "en",
+			expectedVersion:     "sword",
+		},
+		"should take the description of a version of the given version group": {
+			lookupOpts: []LookupOption{WithVersion("sword-shield"), WithLanguages("it")},
+
+			expectedDescription: "testo shield",
+			expectedLanguage:    "it",
+			expectedVersion:     "shield",
+		},
+		"should prefer the version to the languages": {
+			lookupOpts: []LookupOption{WithVersion("sword"), WithLanguages("it")},
+
+			expectedDescription: "sword text",
+			expectedLanguage:    "en",
+			expectedVersion:     "sword",
+		},
+		"should fall back to every version if the version is unknown": {
+			lookupOpts: []LookupOption{WithVersion("pokemon-snap")},
+
+			expectedDescription: "red text",
+			expectedLanguage:    "en",
+			expectedVersion:     "red",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case pokemonSpeciesPath + "/pikachu":
+					w.Write([]byte(speciesResp))
+				case pokemonPath + "/pikachu":
+					w.Write([]byte(`{}`))
+				case versionGroupPath + "/sword-shield":
+					w.Write([]byte(`{"versions": [{"name": "sword"}, {"name": "shield"}]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			found, err := NewClient(httpclient.WithBaseURL(server.URL)).PokemonByName(context.Background(), "pikachu", tt.lookupOpts...)
+			if err != nil {
+				t.Fatalf("received error %v; want nil", err)
+			}
+			if found.Description != tt.expectedDescription {
+				t.Errorf("found description=%s; want %s", found.Description, tt.expectedDescription)
+			}
+			if found.Language != tt.expectedLanguage {
+				t.Errorf("found language=%s; want %s", found.Language, tt.expectedLanguage)
+			}
+			if found.Version != tt.expectedVersion {
+				t.Errorf("found version=%s; want %s", found.Version, tt.expectedVersion)
+			}
+		})
+	}
+
+	t.Run("should not look up a version that is not a slug", func(t *testing.T) {
+		var paths []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paths = append(paths, r.URL.Path)
+			w.Write([]byte(speciesResp))
+		}))
+		defer server.Close()
+
+		found, err := NewClient(httpclient.WithBaseURL(server.URL)).PokemonByName(context.Background(), "pikachu", WithVersion("../../pokemon/ditto"))
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		if found.Version != "red" {
+			t.Errorf("found version=%s; want red", found.Version)
+		}
+		expectedPaths := []string{pokemonSpeciesPath + "/pikachu", pokemonPath + "/pikachu"}
+		if !reflect.DeepEqual(paths, expectedPaths) {
+			t.Errorf("found requests to %v; want %v", paths, expectedPaths)
+		}
+	})
+}
+
+func TestSpecies(t *testing.T) {
+	t.Run("should map the species with a single call", func(t *testing.T) {
+		var calls int
+		server := mockPokeAPIServer(t, "mewtwo", `{
+			"name": "mewtwo",
+			"flavor_text_entries": [
+				{"flavor_text": "texte", "language": {"name": "fr"}, "version": {"name": "red"}},
+				{"flavor_text": "text", "language": {"name": "en"}, "version": {"name": "red"}}
+			],
+			"habitat": {"name": "rare"},
+			"is_legendary": true,
+			"generation": {"name": "generation-i"},
+			"varieties": [{"is_default": true, "pokemon": {"name": "mewtwo"}}]
+		}`, http.StatusOK, func() { calls++ })
+		defer server.Close()
+
+		found, err := NewClient(httpclient.WithBaseURL(server.URL)).Species(context.Background(), "mewtwo")
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		expected := &types.Pokemon{
+			Name:        "mewtwo",
+			Description: "text",
+			Language:    "en",
+			Version:     "red",
+			Habitat:     "rare",
+			IsLegendary: true,
+			Generation:  "generation-i",
+		}
+		if !reflect.DeepEqual(found, expected) {
+			t.Errorf("Species(mewtwo) = %#v; want %#v", found, expected)
+		}
+		if calls != 1 {
+			t.Errorf("found %d calls; want 1", calls)
+		}
+	})
+
+	t.Run("should respond with the pokemon not found error if the species does not exist", func(t *testing.T) {
+		server := mockPokeAPIServer(t, "missingno", "Not Found", http.StatusNotFound, func() {})
+		defer server.Close()
+
+		_, err := NewClient(httpclient.WithBaseURL(server.URL)).Species(context.Background(), "missingno")
+		if !errors.Is(err, ErrPokemonNotFound) {
+			t.Errorf("received error %v; want %v", err, ErrPokemonNotFound)
+		}
+	})
+}
+
+func TestDescriptions(t *testing.T) {
+	t.Run("should key the descriptions by version and language", func(t *testing.T) {
+		server := mockPokeAPIServer(t, "pikachu", `{
+			"name": "pikachu",
+			"flavor_text_entries": [
+				{"flavor_text": "red text", "language": {"name": "en"}, "version": {"name": "red"}},
+				{"flavor_text": "texte rouge", "language": {"name": "fr"}, "version": {"name": "red"}},
+				{"flavor_text": "sword text", "language": {"name": "en"}, "version": {"name": "sword"}},
+				{"flavor_text": "duplicated sword text", "language": {"name": "en"}, "version": {"name": "sword"}}
+			]
+		}`, http.StatusOK, func() {})
+		defer server.Close()
+
+		found, err := NewClient(httpclient.WithBaseURL(server.URL)).Descriptions(context.Background(), "pikachu")
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		expected := types.Descriptions{
+			"red":   {"en": "red text", "fr": "texte rouge"},
+			"sword": {"en": "sword text"},
+		}
+		if !reflect.DeepEqual(found, expected) {
+			t.Errorf("Descriptions(pikachu) = %#v; want %#v", found, expected)
+		}
+	})
+
+	t.Run("should respond with the pokemon not found error if the species does not exist", func(t *testing.T) {
+		server := mockPokeAPIServer(t, "missingno", "Not Found", http.StatusNotFound, func() {})
+		defer server.Close()
+
+		_, err := NewClient(httpclient.WithBaseURL(server.URL)).Descriptions(context.Background(), "missingno")
+		if !errors.Is(err, ErrPokemonNotFound) {
+			t.Errorf("received error %v; want %v", err, ErrPokemonNotFound)
+		}
+	})
+}
+
+func TestEvolutionChain(t *testing.T) {
+	t.Run("should map a branching evolution chain with its triggers", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case pokemonSpeciesPath + "/vaporeon":
+				w.Write([]byte(`{
+					"name": "vaporeon",
+					"evolution_chain": {"url": "https://pokeapi.co/api/v2/evolution-chain/67/"}
+				}`))
+			case evolutionChainPath + "/67":
+				w.Write([]byte(`{
+					"id": 67,
+					"chain": {
+						"species": {"name": "eevee"},
+						"evolution_details": [],
+						"evolves_to": [
+							{
+								"species": {"name": "vaporeon"},
+								"evolution_details": [
+									{"trigger": {"name": "use-item"}, "item": {"name": "water-stone"}, "min_level": null, "time_of_day": ""}
+								],
+								"evolves_to": []
+							},
+							{
+								"species": {"name": "umbreon"},
+								"evolution_details": [
+									{"trigger": {"name": "level-up"}, "item": null, "min_happiness": 160, "time_of_day": "night"}
+								],
+								"evolves_to": []
+							}
+						]
+					}
+				}`))
+			default:
+				t.Errorf("unexpected request to %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		found, err := NewClient(httpclient.WithBaseURL(server.URL)).EvolutionChain(context.Background(), "vaporeon")
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		expected := &types.EvolutionNode{
+			Species: "eevee",
+			EvolvesTo: []types.EvolutionNode{
+				{
+					Species:   "vaporeon",
+					Triggers:  []types.EvolutionTrigger{{Trigger: "use-item", Item: "water-stone"}},
+					EvolvesTo: []types.EvolutionNode{},
+				},
+				{
+					Species:   "umbreon",
+					Triggers:  []types.EvolutionTrigger{{Trigger: "level-up", MinHappiness: 160, TimeOfDay: "night"}},
+					EvolvesTo: []types.EvolutionNode{},
+				},
+			},
+		}
+		if !reflect.DeepEqual(found, expected) {
+			t.Errorf("EvolutionChain(vaporeon) = %#v; want %#v", found, expected)
+		}
+	})
+
+	t.Run("should respond with the pokemon not found error if the species does not exist", func(t *testing.T) {
+		server := mockPokeAPIServer(t, "missingno", "Not Found", http.StatusNotFound, func() {})
+		defer server.Close()
+
+		_, err := NewClient(httpclient.WithBaseURL(server.URL)).EvolutionChain(context.Background(), "missingno")
+		if !errors.Is(err, ErrPokemonNotFound) {
+			t.Errorf("received error %v; want %v", err, ErrPokemonNotFound)
+		}
+	})
+}
+
+func TestSpeciesNames(t *testing.T) {
+	t.Run("should page through the whole species list", func(t *testing.T) {
+		var foundQueries []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != pokemonSpeciesPath {
+				t.Errorf("unexpected request to %s", r.URL.Path)
+			}
+			foundQueries = append(foundQueries, r.URL.RawQuery)
+			switch r.URL.Query().Get("offset") {
+			case "0":
+				w.Write([]byte(`{"count": 3, "results": [
+					{"name": "bulbasaur", "url": "https://pokeapi.co/api/v2/pokemon-species/1/"},
+					{"name": "ivysaur", "url": "https://pokeapi.co/api/v2/pokemon-species/2/"}
+				]}`))
+			default:
+				w.Write([]byte(`{"count": 3, "results": [
+					{"name": "venusaur", "url": "https://pokeapi.co/api/v2/pokemon-species/3/"}
+				]}`))
+			}
+		}))
+		defer server.Close()
+
+		found, err := SpeciesNames(context.Background(), NewClient(httpclient.WithBaseURL(server.URL)), 2)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		if expected := []string{"bulbasaur", "ivysaur", "venusaur"}; !reflect.DeepEqual(found, expected) {
+			t.Errorf("SpeciesNames() = %v; want %v", found, expected)
+		}
+		if expected := []string{"limit=2&offset=0", "limit=2&offset=2"}; !reflect.DeepEqual(foundQueries, expected) {
+			t.Errorf("found queries=%v; want %v", foundQueries, expected)
+		}
+	})
+
+	t.Run("should stop at the first empty page", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.Write([]byte(`{"count": 1000, "results": []}`))
+		}))
+		defer server.Close()
+
+		found, err := SpeciesNames(context.Background(), NewClient(httpclient.WithBaseURL(server.URL)), 0)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		if len(found) != 0 || calls != 1 {
+			t.Errorf("found names=%v, calls=%d; want no names and 1 call", found, calls)
+		}
+	})
+}
+
+func TestListSpeciesBy(t *testing.T) {
+	tests := map[string]struct {
+		filter       SpeciesFilter
+		value        string
+		mockResponse string
+		mockStatus   int
+
+		expectedSpecies []SpeciesRef
+		expectedError   error
+	}{
+		"should list the species of a habitat in National Pokédex order": {
+			filter: FilterHabitat,
+			value:  "cave",
+			mockResponse: `{"name": "cave", "pokemon_species": [
+				{"name": "geodude", "url": "https://pokeapi.co/api/v2/pokemon-species/74/"},
+				{"name": "zubat", "url": "https://pokeapi.co/api/v2/pokemon-species/41/"}
+			]}`,
+			mockStatus: http.StatusOK,
+
+			expectedSpecies: []SpeciesRef{{41, "zubat"}, {74, "geodude"}},
+		},
+		"should list the species of the default varieties of a type": {
+			filter: FilterType,
+			value:  "fire",
+			mockResponse: `{"name": "fire", "pokemon": [
+				{"slot": 1, "pokemon": {"name": "charmander", "url": "https://pokeapi.co/api/v2/pokemon/4/"}},
+				{"slot": 1, "pokemon": {"name": "charizard-mega-x", "url": "https://pokeapi.co/api/v2/pokemon/10034/"}}
+			]}`,
+			mockStatus: http.StatusOK,
+
+			expectedSpecies: []SpeciesRef{{4, "charmander"}},
+		},
+		"should respond with the filter not found error if the category does not exist": {
+			filter:       FilterColor,
+			value:        "ultraviolet",
+			mockResponse: "Not Found",
+			mockStatus:   http.StatusNotFound,
+
+			expectedError: ErrFilterNotFound,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if expected := "/" + string(tt.filter) + "/" + tt.value; r.URL.Path != expected {
+					t.Errorf("found path=%s; want %s", r.URL.Path, expected)
+				}
+				w.WriteHeader(tt.mockStatus)
+				w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			found, err := NewClient(httpclient.WithBaseURL(server.URL)).ListSpeciesBy(context.Background(), tt.filter, tt.value)
+			if !errors.Is(err, tt.expectedError) {
+				t.Errorf("received error %v; want %v", err, tt.expectedError)
+			}
+			if !reflect.DeepEqual(found, tt.expectedSpecies) {
+				t.Errorf("ListSpeciesBy(%s, %s) = %#v; want %#v", tt.filter, tt.value, found, tt.expectedSpecies)
+			}
+		})
+	}
+}
+
 func mockPokeAPIServer(
 	t *testing.T,
 	pokemonName string,
@@ -157,15 +737,39 @@ func mockPokeAPIServer(
 ) *httptest.Server {
 	t.Helper()
 
+	return mockPokeAPIServerWithDetails(t, pokemonName, mockResp, statusCode, pokemonName, `{}`, assertCalled)
+}
+
+// mockPokeAPIServerWithDetails serves the species resource and the pokemon resource of its default variety
+func mockPokeAPIServerWithDetails(
+	t *testing.T,
+	pokemonName string,
+	mockResp string,
+	statusCode int,
+	varietyName string,
+	mockPokemonResp string,
+	assertCalled func(),
+) *httptest.Server {
+	t.Helper()
+
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assertCalled()
-		expectedPath := pokemonSpeciesPath + "/" + pokemonName
-		if r.URL.Path != expectedPath {
-			t.Errorf("Expected to request %s, got: %s", expectedPath, r.URL.Path)
+		expectedSpeciesPath := pokemonSpeciesPath + "/" + pokemonName
+		expectedPokemonPath := pokemonPath + "/" + varietyName
+		var resp string
+		switch r.URL.Path {
+		case expectedSpeciesPath:
+			w.WriteHeader(statusCode)
+			resp = mockResp
+		case expectedPokemonPath:
+			resp = mockPokemonResp
+		default:
+			t.Errorf("Expected to request %s or %s, got: %s", expectedSpeciesPath, expectedPokemonPath, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
 		}
-		w.WriteHeader(statusCode)
 
-		_, err := w.Write([]byte(mockResp))
+		_, err := w.Write([]byte(resp))
 		if err != nil {
 			t.Errorf("Expect nil err, got %s", err)
 		}
@@ -181,3 +785,130 @@ func TestNew(t *testing.T) {
 		}
 	})
 }
+
+func TestNewWithOptions(t *testing.T) {
+	t.Run("requests should carry the configured user agent and headers", func(t *testing.T) {
+		var foundUserAgent, foundHeader string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			foundUserAgent = r.Header.Get("User-Agent")
+			foundHeader = r.Header.Get("X-Mirror-Token")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		opts := []httpclient.Option{
+			httpclient.WithBaseURL(server.URL),
+			httpclient.WithUserAgent("pokedex-test"),
+			httpclient.WithHeader("X-Mirror-Token", "secret"),
+		}
+		if _, err := NewClient(opts...).PokemonByName(context.Background(), "pikachu"); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+		if foundUserAgent != "pokedex-test" {
+			t.Errorf("found User-Agent=%s; want pokedex-test", foundUserAgent)
+		}
+		if foundHeader != "secret" {
+			t.Errorf("found X-Mirror-Token=%s; want secret", foundHeader)
+		}
+	})
+}
+
+func TestPokemonByNameRetries(t *testing.T) {
+	t.Run("should retry a transient failure of the api", func(t *testing.T) {
+		var calls int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == pokemonPath+"/pikachu" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"name": "pikachu", "habitat": {"name": "forest"}}`))
+		}))
+		defer server.Close()
+
+		pokemonClient := NewClient(
+			httpclient.WithBaseURL(server.URL),
+			httpclient.WithRetryPolicy(retry.Policy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}),
+		)
+		found, err := pokemonClient.PokemonByName(context.Background(), "pikachu")
+		if err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+
+		expected := &types.Pokemon{Name: "pikachu", Habitat: "forest"}
+		if !reflect.DeepEqual(found, expected) {
+			t.Errorf("PokemonByName(pikachu) = %#v; want %#v", found, expected)
+		}
+		if calls != 2 {
+			t.Errorf("found %d calls; want 2", calls)
+		}
+	})
+}
+
+func TestPokemonByNameUpstreamError(t *testing.T) {
+	t.Run("should describe the failed call with an UpstreamError", func(t *testing.T) {
+		server := mockPokeAPIServer(t, "nonexisting", "Not Found", http.StatusNotFound, func() {})
+		defer server.Close()
+		pokemonClient := NewClient(httpclient.WithBaseURL(server.URL))
+
+		_, err := pokemonClient.PokemonByName(context.Background(), "nonexisting")
+
+		var upstreamErr *apiclients.UpstreamError
+		if !errors.As(err, &upstreamErr) {
+			t.Fatalf("received error %v; want an UpstreamError", err)
+		}
+		expected := &apiclients.UpstreamError{
+			Service:     serviceName,
+			URL:         server.URL + pokemonSpeciesPath + "/nonexisting",
+			StatusCode:  http.StatusNotFound,
+			Retryable:   false,
+			BodySnippet: "Not Found",
+			Err:         ErrPokemonNotFound,
+		}
+		if !reflect.DeepEqual(upstreamErr, expected) {
+			t.Errorf("found %#v; want %#v", upstreamErr, expected)
+		}
+	})
+
+	t.Run("should describe a malformed body with an UpstreamError", func(t *testing.T) {
+		server := mockPokeAPIServer(t, "pikachu", `{"name": `, http.StatusOK, func() {})
+		defer server.Close()
+		pokemonClient := NewClient(httpclient.WithBaseURL(server.URL))
+
+		_, err := pokemonClient.PokemonByName(context.Background(), "pikachu")
+
+		var upstreamErr *apiclients.UpstreamError
+		if !errors.As(err, &upstreamErr) {
+			t.Fatalf("received error %v; want an UpstreamError", err)
+		}
+		if !errors.Is(err, ErrUnknown) {
+			t.Errorf("received error %v; want %v", err, ErrUnknown)
+		}
+		if expectedURL := server.URL + pokemonSpeciesPath + "/pikachu"; upstreamErr.URL != expectedURL {
+			t.Errorf("found URL %s; want %s", upstreamErr.URL, expectedURL)
+		}
+		if upstreamErr.StatusCode != http.StatusOK || upstreamErr.Retryable {
+			t.Errorf("found status code %d and retryable %v; want %d and false", upstreamErr.StatusCode, upstreamErr.Retryable, http.StatusOK)
+		}
+	})
+
+	t.Run("should describe a malformed species reference with an UpstreamError", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"count": 1, "results": [{"name": "bulbasaur", "url": "not a url"}]}`))
+		}))
+		defer server.Close()
+		pokemonClient := NewClient(httpclient.WithBaseURL(server.URL))
+
+		_, err := pokemonClient.ListSpecies(context.Background(), 0, 1)
+
+		var upstreamErr *apiclients.UpstreamError
+		if !errors.As(err, &upstreamErr) || upstreamErr.Service != serviceName {
+			t.Fatalf("received error %v; want a pokeapi UpstreamError", err)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// DefaultListPageSize is the number of species AllSpecies requests at once
+const DefaultListPageSize = 200
+
+// firstVarietyID is the ID of the first pokemon that is not the default variety of its species,
+// e.g. a mega evolution; the default ones share the ID of their species
+const firstVarietyID = 10001
+
+// SpeciesRef identifies a species
+type SpeciesRef struct {
+	// ID is the National Pokédex number of the species
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// SpeciesPage is a page of the list of the species
+type SpeciesPage struct {
+	// Count is the number of species in the whole list
+	Count int `json:"count"`
+
+	Species []SpeciesRef `json:"species"`
+}
+
+// SpeciesFilter is a category of species listed by the PokeAPI, its values are the names of the category resources
+type SpeciesFilter string
+
+const (
+	// FilterHabitat selects the species living in a habitat, e.g. "cave"
+	FilterHabitat SpeciesFilter = "pokemon-habitat"
+
+	// FilterGeneration selects the species introduced in a generation, e.g. "generation-i"
+	FilterGeneration SpeciesFilter = "generation"
+
+	// FilterColor selects the species of a Pokédex color, e.g. "yellow"
+	FilterColor SpeciesFilter = "pokemon-color"
+
+	// FilterShape selects the species of a Pokédex shape, e.g. "quadruped"
+	FilterShape SpeciesFilter = "pokemon-shape"
+
+	// FilterType selects the species whose default variety has a type, e.g. "fire"
+	FilterType SpeciesFilter = "type"
+)
+
+// AllSpecies retrieves every species, requesting them to client pageSize at a time
+func AllSpecies(ctx context.Context, client Client, pageSize int) ([]SpeciesRef, error) {
+	if pageSize <= 0 {
+		pageSize = DefaultListPageSize
+	}
+	var species []SpeciesRef
+	for {
+		page, err := client.ListSpecies(ctx, len(species), pageSize)
+		if err != nil {
+			return nil, err
+		}
+		species = append(species, page.Species...)
+		if len(page.Species) == 0 || len(species) >= page.Count {
+			return species, nil
+		}
+	}
+}
+
+// SpeciesNames retrieves the names of every species, requesting them to client pageSize at a time
+func SpeciesNames(ctx context.Context, client Client, pageSize int) ([]string, error) {
+	species, err := AllSpecies(ctx, client, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(species))
+	for _, s := range species {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+// speciesRef identifies the species of a pokeapi resource by the ID in its URL
+func speciesRef(resource namedAPIResource) (SpeciesRef, error) {
+	id, err := resourceID(resource.URL)
+	if err != nil {
+		return SpeciesRef{}, err
+	}
+	number, err := strconv.Atoi(id)
+	if err != nil {
+		return SpeciesRef{}, fmt.Errorf("resource ID %q is not a number: %w", id, err)
+	}
+	return SpeciesRef{ID: number, Name: resource.Name}, nil
+}
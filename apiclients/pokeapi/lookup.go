@@ -0,0 +1,95 @@
+package pokeapi
+
+import (
+	"slices"
+	"strings"
+)
+
+// DefaultLanguage is the language of the description when none of the preferred ones is available
+const DefaultLanguage = "en"
+
+// LookupOptions tunes the result of a PokemonByName call
+type LookupOptions struct {
+	// Languages are the preferred languages of the description, most preferred first.
+	// They are matched against the pokeapi language names, e.g. "it" or "ja-Hrkt",
+	// first exactly and then by their primary subtag, so that "it-IT" selects "it".
+	Languages []string
+
+	// Version is the game version, e.g. "sword", or version group, e.g. "sword-shield",
+	// the description is taken from. When the species has no description in it,
+	// the description is selected among the ones of every version.
+	Version string
+}
+
+type LookupOption func(*LookupOptions)
+
+// WithLanguages sets the preferred languages of the description, most preferred first
+func WithLanguages(languages ...string) LookupOption {
+	return func(o *LookupOptions) {
+		o.Languages = append(o.Languages, languages...)
+	}
+}
+
+// WithVersion sets the game version or version group the description is taken from
+func WithVersion(version string) LookupOption {
+	return func(o *LookupOptions) {
+		o.Version = version
+	}
+}
+
+// NewLookupOptions returns the LookupOptions resulting from applying opts in order
+func NewLookupOptions(opts ...LookupOption) LookupOptions {
+	o := LookupOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// selectDescription returns the description in the most preferred available language,
+// falling back to DefaultLanguage, preferring the descriptions of the given versions if any.
+// It returns false if no description is available in these languages.
+func selectDescription(entries []flavorText, languages []string, versions []string) (flavorText, bool) {
+	if len(versions) > 0 {
+		inVersions := slices.DeleteFunc(slices.Clone(entries), func(entry flavorText) bool {
+			return !slices.Contains(versions, entry.Version.Name)
+		})
+		if entry, ok := selectDescription(inVersions, languages, nil); ok {
+			return entry, true
+		}
+	}
+	for _, language := range append(slices.Clone(languages), DefaultLanguage) {
+		if entry, ok := findDescription(entries, language); ok {
+			return entry, true
+		}
+	}
+	return flavorText{}, false
+}
+
+// hasVersion reports whether any entry belongs to the given version
+func hasVersion(entries []flavorText, version string) bool {
+	return slices.ContainsFunc(entries, func(entry flavorText) bool {
+		return entry.Version.Name == version
+	})
+}
+
+// findDescription returns the first entry in the given language, or else the first one
+// sharing its primary subtag, e.g. "ja-Hrkt" for "ja"
+func findDescription(entries []flavorText, language string) (flavorText, bool) {
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Language.Name, language) {
+			return entry, true
+		}
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(primarySubtag(entry.Language.Name), primarySubtag(language)) {
+			return entry, true
+		}
+	}
+	return flavorText{}, false
+}
+
+func primarySubtag(language string) string {
+	primary, _, _ := strings.Cut(language, "-")
+	return primary
+}
@@ -4,10 +4,14 @@ package pokeapi
 //
 // Reference: https://pokeapi.co/docs/v2#pokemonspecies
 type pokemonSpecies struct {
-	Name              string         `json:"name"`
-	FlavorTextEntries []flavorText   `json:"flavor_text_entries"`
-	Habitat           pokemonHabitat `json:"habitat"`
-	IsLegendary       bool           `json:"is_legendary"`
+	Name              string           `json:"name"`
+	FlavorTextEntries []flavorText     `json:"flavor_text_entries"`
+	Habitat           pokemonHabitat   `json:"habitat"`
+	IsLegendary       bool             `json:"is_legendary"`
+	IsMythical        bool             `json:"is_mythical"`
+	Generation        namedAPIResource `json:"generation"`
+	Varieties         []variety        `json:"varieties"`
+	EvolutionChain    apiResource      `json:"evolution_chain"`
 }
 
 // flavorText is a partial representation of the `FlavorText` pokeapi type
@@ -18,6 +22,7 @@ type flavorText struct {
 	Language   struct {
 		Name string `json:"name"`
 	} `json:"language"`
+	Version namedAPIResource `json:"version"`
 }
 
 // pokemonHabitat is a partial representation of the `PokemonHabitat` pokeapi type
@@ -26,3 +31,130 @@ type flavorText struct {
 type pokemonHabitat struct {
 	Name string `json:"name"`
 }
+
+// variety is a partial representation of the `PokemonSpeciesVariety` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#pokemonspeciesvariety
+type variety struct {
+	IsDefault bool             `json:"is_default"`
+	Pokemon   namedAPIResource `json:"pokemon"`
+}
+
+// namedAPIResource is a partial representation of the `NamedAPIResource` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#namedapiresource
+type namedAPIResource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// namedAPIResourceList is a partial representation of the `NamedAPIResourceList` pokeapi type,
+// the page of a resource list
+//
+// Reference: https://pokeapi.co/docs/v2#namedapiresourcelist
+type namedAPIResourceList struct {
+	Count   int                `json:"count"`
+	Results []namedAPIResource `json:"results"`
+}
+
+// speciesCategory is the partial representation shared by the pokeapi types grouping species,
+// i.e. `PokemonHabitat`, `Generation`, `PokemonColor` and `PokemonShape`, and by the `Type` one, grouping pokemon
+//
+// Reference: https://pokeapi.co/docs/v2#pokemonhabitat
+type speciesCategory struct {
+	PokemonSpecies []namedAPIResource `json:"pokemon_species"`
+	Pokemon        []typePokemon      `json:"pokemon"`
+}
+
+// typePokemon is a partial representation of the `TypePokemon` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#typepokemon
+type typePokemon struct {
+	Slot    int              `json:"slot"`
+	Pokemon namedAPIResource `json:"pokemon"`
+}
+
+// apiResource is a representation of the `APIResource` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#apiresource
+type apiResource struct {
+	URL string `json:"url"`
+}
+
+// pokemon is a partial representation of the `Pokemon` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#pokemon
+type pokemon struct {
+	Name           string           `json:"name"`
+	BaseExperience int              `json:"base_experience"`
+	Height         int              `json:"height"`
+	Weight         int              `json:"weight"`
+	Abilities      []pokemonAbility `json:"abilities"`
+	Stats          []pokemonStat    `json:"stats"`
+	Types          []pokemonType    `json:"types"`
+}
+
+// pokemonAbility is a partial representation of the `PokemonAbility` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#pokemonability
+type pokemonAbility struct {
+	IsHidden bool             `json:"is_hidden"`
+	Slot     int              `json:"slot"`
+	Ability  namedAPIResource `json:"ability"`
+}
+
+// pokemonStat is a partial representation of the `PokemonStat` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#pokemonstat
+type pokemonStat struct {
+	Stat     namedAPIResource `json:"stat"`
+	BaseStat int              `json:"base_stat"`
+}
+
+// pokemonType is a partial representation of the `PokemonType` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#pokemontype
+type pokemonType struct {
+	Slot int              `json:"slot"`
+	Type namedAPIResource `json:"type"`
+}
+
+// evolutionChain is a partial representation of the `EvolutionChain` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#evolutionchain
+type evolutionChain struct {
+	Chain chainLink `json:"chain"`
+}
+
+// chainLink is a representation of the `ChainLink` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#chainlink
+type chainLink struct {
+	Species          namedAPIResource  `json:"species"`
+	EvolutionDetails []evolutionDetail `json:"evolution_details"`
+	EvolvesTo        []chainLink       `json:"evolves_to"`
+}
+
+// evolutionDetail is a partial representation of the `EvolutionDetail` pokeapi type,
+// the unset conditions are null and decode to zero values
+//
+// Reference: https://pokeapi.co/docs/v2#evolutiondetail
+type evolutionDetail struct {
+	Trigger       namedAPIResource `json:"trigger"`
+	MinLevel      int              `json:"min_level"`
+	Item          namedAPIResource `json:"item"`
+	HeldItem      namedAPIResource `json:"held_item"`
+	TradeSpecies  namedAPIResource `json:"trade_species"`
+	MinHappiness  int              `json:"min_happiness"`
+	MinAffection  int              `json:"min_affection"`
+	TimeOfDay     string           `json:"time_of_day"`
+	KnownMoveType namedAPIResource `json:"known_move_type"`
+	Location      namedAPIResource `json:"location"`
+}
+
+// versionGroup is a partial representation of the `VersionGroup` pokeapi type
+//
+// Reference: https://pokeapi.co/docs/v2#versiongroup
+type versionGroup struct {
+	Versions []namedAPIResource `json:"versions"`
+}
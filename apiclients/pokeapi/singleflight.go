@@ -0,0 +1,123 @@
+package pokeapi
+
+import (
+	"context"
+	"fmt"
+	"malta895/pokedex/apiclients/singleflight"
+	"malta895/pokedex/types"
+	"maps"
+	"slices"
+)
+
+type singleflightClient struct {
+	next Client
+
+	pokemon      singleflight.Group[*types.Pokemon]
+	speciesData  singleflight.Group[*types.Pokemon]
+	speciesPages singleflight.Group[*SpeciesPage]
+	species      singleflight.Group[[]SpeciesRef]
+	descriptions singleflight.Group[types.Descriptions]
+	chains       singleflight.Group[*types.EvolutionNode]
+}
+
+// NewSingleflightClient returns a Client sharing a single call to next among the concurrent identical calls,
+// e.g. the lookups of a trending pokemon. Every caller receives its own copy of the result.
+func NewSingleflightClient(next Client) Client {
+	return &singleflightClient{next: next}
+}
+
+func (c *singleflightClient) PokemonByName(ctx context.Context, name string, opts ...LookupOption) (*types.Pokemon, error) {
+	pokemon, shared, err := c.pokemon.Do(ctx, pokemonCacheKey(name, NewLookupOptions(opts...)),
+		func(ctx context.Context) (*types.Pokemon, error) {
+			return c.next.PokemonByName(ctx, name, opts...)
+		})
+	if shared {
+		pokemon = clonePokemon(pokemon)
+	}
+	return pokemon, err
+}
+
+func (c *singleflightClient) Species(ctx context.Context, name string) (*types.Pokemon, error) {
+	pokemon, shared, err := c.speciesData.Do(ctx, name, func(ctx context.Context) (*types.Pokemon, error) {
+		return c.next.Species(ctx, name)
+	})
+	if shared {
+		pokemon = clonePokemon(pokemon)
+	}
+	return pokemon, err
+}
+
+func (c *singleflightClient) ListSpecies(ctx context.Context, offset, limit int) (*SpeciesPage, error) {
+	page, shared, err := c.speciesPages.Do(ctx, fmt.Sprintf("offset=%d&limit=%d", offset, limit),
+		func(ctx context.Context) (*SpeciesPage, error) {
+			return c.next.ListSpecies(ctx, offset, limit)
+		})
+	if shared && page != nil {
+		page = &SpeciesPage{Count: page.Count, Species: slices.Clone(page.Species)}
+	}
+	return page, err
+}
+
+func (c *singleflightClient) ListSpeciesBy(ctx context.Context, filter SpeciesFilter, value string) ([]SpeciesRef, error) {
+	species, shared, err := c.species.Do(ctx, fmt.Sprintf("%s/%s", filter, value),
+		func(ctx context.Context) ([]SpeciesRef, error) {
+			return c.next.ListSpeciesBy(ctx, filter, value)
+		})
+	if shared {
+		species = slices.Clone(species)
+	}
+	return species, err
+}
+
+func (c *singleflightClient) Descriptions(ctx context.Context, name string) (types.Descriptions, error) {
+	descriptions, shared, err := c.descriptions.Do(ctx, name, func(ctx context.Context) (types.Descriptions, error) {
+		return c.next.Descriptions(ctx, name)
+	})
+	if shared {
+		descriptions = cloneDescriptions(descriptions)
+	}
+	return descriptions, err
+}
+
+func (c *singleflightClient) EvolutionChain(ctx context.Context, name string) (*types.EvolutionNode, error) {
+	chain, shared, err := c.chains.Do(ctx, name, func(ctx context.Context) (*types.EvolutionNode, error) {
+		return c.next.EvolutionChain(ctx, name)
+	})
+	if shared && chain != nil {
+		cloned := cloneEvolutionNode(*chain)
+		chain = &cloned
+	}
+	return chain, err
+}
+
+func clonePokemon(pokemon *types.Pokemon) *types.Pokemon {
+	if pokemon == nil {
+		return nil
+	}
+	cloned := *pokemon
+	cloned.Types = slices.Clone(pokemon.Types)
+	cloned.Abilities = slices.Clone(pokemon.Abilities)
+	cloned.BaseStats = maps.Clone(pokemon.BaseStats)
+	return &cloned
+}
+
+func cloneDescriptions(descriptions types.Descriptions) types.Descriptions {
+	if descriptions == nil {
+		return nil
+	}
+	cloned := make(types.Descriptions, len(descriptions))
+	for version, byLanguage := range descriptions {
+		cloned[version] = maps.Clone(byLanguage)
+	}
+	return cloned
+}
+
+func cloneEvolutionNode(node types.EvolutionNode) types.EvolutionNode {
+	cloned := node
+	cloned.Triggers = slices.Clone(node.Triggers)
+	cloned.EvolvesTo = make([]types.EvolutionNode, 0, len(node.EvolvesTo))
+	for _, next := range node.EvolvesTo {
+		cloned.EvolvesTo = append(cloned.EvolvesTo, cloneEvolutionNode(next))
+	}
+	return cloned
+}
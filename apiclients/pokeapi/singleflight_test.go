@@ -0,0 +1,101 @@
+package pokeapi
+
+import (
+	"context"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/testutils"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSingleflightClient(t *testing.T) {
+	t.Run("should make a single upstream call for concurrent lookups of the same pokemon", func(t *testing.T) {
+		var speciesHits, pokemonHits atomic.Int32
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case pokemonSpeciesPath + "/pikachu":
+				speciesHits.Add(1)
+				<-release
+				w.Write([]byte(`{
+					"name": "pikachu",
+					"flavor_text_entries": [{"flavor_text": "mouse", "language": {"name": "en"}}],
+					"habitat": {"name": "forest"}
+				}`))
+			case pokemonPath + "/pikachu":
+				pokemonHits.Add(1)
+				w.Write([]byte(`{"name": "pikachu", "types": [{"slot": 1, "type": {"name": "electric"}}]}`))
+			default:
+				t.Errorf("unexpected request to %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+		client := NewSingleflightClient(NewClient(httpclient.WithBaseURL(server.URL)))
+
+		const callers = 20
+		var wg, joined sync.WaitGroup
+		joined.Add(callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			ctx := testutils.WaitingContext(context.Background(), joined.Done)
+			go func() {
+				defer wg.Done()
+				pokemon, err := client.PokemonByName(ctx, "pikachu")
+				if err != nil {
+					t.Errorf("received error %v; want nil", err)
+					return
+				}
+				if pokemon.Description != "mouse" || !reflect.DeepEqual(pokemon.Types, []string{"electric"}) {
+					t.Errorf("found pokemon %+v; want the mock pikachu", pokemon)
+				}
+				// every caller owns its copy, the race detector reports shared ones
+				pokemon.Description = "translated mouse"
+				pokemon.Types[0] = "translated electric"
+			}()
+		}
+		// every caller joined the call in flight once it waits for its result
+		joined.Wait()
+		close(release)
+		wg.Wait()
+
+		if found := speciesHits.Load(); found != 1 {
+			t.Errorf("found %d species requests; want 1", found)
+		}
+		if found := pokemonHits.Load(); found != 1 {
+			t.Errorf("found %d pokemon requests; want 1", found)
+		}
+	})
+
+	t.Run("should make separate upstream calls for different lookup options", func(t *testing.T) {
+		var speciesHits atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == pokemonSpeciesPath+"/pikachu" {
+				speciesHits.Add(1)
+			}
+			w.Write([]byte(`{"name": "pikachu"}`))
+		}))
+		defer server.Close()
+		client := NewSingleflightClient(NewClient(httpclient.WithBaseURL(server.URL)))
+
+		var wg sync.WaitGroup
+		for _, language := range []string{"en", "it"} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := client.PokemonByName(context.Background(), "pikachu", WithLanguages(language)); err != nil {
+					t.Errorf("received error %v; want nil", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if found := speciesHits.Load(); found != 2 {
+			t.Errorf("found %d species requests; want 2", found)
+		}
+	})
+}
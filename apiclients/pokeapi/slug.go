@@ -0,0 +1,55 @@
+package pokeapi
+
+import "strings"
+
+// slugReplacer spells out the characters of the species names that have a meaning in their slug,
+// and drops the punctuation that does not separate words
+var slugReplacer = strings.NewReplacer(
+	"♀", "-f", "♂", "-m",
+	"á", "a", "à", "a", "â", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+	"'", "", "’", "", ".", "", ":", "",
+)
+
+// Slug turns a pokemon identifier into the one understood by the PokeAPI:
+// National Pokédex numbers lose their leading zeros, e.g. "#025" becomes "25",
+// names are lowercased and their words joined by hyphens, e.g. "Mr. Mime" becomes "mr-mime",
+// "Farfetch'd" becomes "farfetchd" and "Nidoran♀" becomes "nidoran-f".
+// It returns an empty string if identifier has nothing in common with a PokeAPI name.
+func Slug(identifier string) string {
+	identifier = strings.TrimSpace(identifier)
+	if number := strings.TrimPrefix(identifier, "#"); isNumber(number) {
+		if number = strings.TrimLeft(number, "0"); number == "" {
+			return "0"
+		}
+		return number
+	}
+
+	var slug strings.Builder
+	for _, r := range slugReplacer.Replace(strings.ToLower(identifier)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			slug.WriteRune(r)
+		case !strings.HasSuffix(slug.String(), "-"):
+			// any other character separates words, e.g. spaces and underscores
+			slug.WriteRune('-')
+		}
+	}
+	return strings.Trim(slug.String(), "-")
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package pokeapi
+
+import "testing"
+
+func TestSlug(t *testing.T) {
+	tests := map[string]struct {
+		identifier string
+
+		expected string
+	}{
+		"should keep a slug untouched":                       {identifier: "pikachu", expected: "pikachu"},
+		"should keep a hyphenated slug untouched":            {identifier: "ho-oh", expected: "ho-oh"},
+		"should lowercase names":                             {identifier: "PiKaChU", expected: "pikachu"},
+		"should keep a national dex number":                  {identifier: "25", expected: "25"},
+		"should drop the leading zeros of a dex number":      {identifier: "#025", expected: "25"},
+		"should join words with hyphens":                     {identifier: "Mr. Mime", expected: "mr-mime"},
+		"should drop apostrophes":                            {identifier: "Farfetch'd", expected: "farfetchd"},
+		"should drop typographic apostrophes":                {identifier: "Sirfetch’d", expected: "sirfetchd"},
+		"should spell out the female sign":                   {identifier: "Nidoran♀", expected: "nidoran-f"},
+		"should spell out the male sign":                     {identifier: "nidoran♂", expected: "nidoran-m"},
+		"should drop accents":                                {identifier: "Flabébé", expected: "flabebe"},
+		"should drop colons":                                 {identifier: "Type: Null", expected: "type-null"},
+		"should drop trailing punctuation":                   {identifier: "Mime Jr.", expected: "mime-jr"},
+		"should collapse separators":                         {identifier: "  tapu__koko  ", expected: "tapu-koko"},
+		"should return an empty slug for unrelated names":    {identifier: "ピカチュウ", expected: ""},
+		"should return an empty slug for an empty name":      {identifier: "", expected: ""},
+		"should keep digits within names":                    {identifier: "Porygon2", expected: "porygon2"},
+		"should not treat a name starting with digits as id": {identifier: "25pikachu", expected: "25pikachu"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if found := Slug(tt.identifier); found != tt.expected {
+				t.Errorf("Slug(%q) = %q; want %q", tt.identifier, found, tt.expected)
+			}
+		})
+	}
+}
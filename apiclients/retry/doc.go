@@ -0,0 +1,3 @@
+// Package retry provides the retry policy shared by the API clients,
+// to recover from transient failures of the external APIs.
+package retry
@@ -0,0 +1,197 @@
+package retry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the default number of attempts, including the first one
+	DefaultMaxAttempts = 3
+
+	// DefaultBaseDelay is the default delay before the first retry
+	DefaultBaseDelay = 100 * time.Millisecond
+
+	// DefaultMaxDelay is the default upper bound of the delay between two attempts
+	DefaultMaxDelay = 2 * time.Second
+)
+
+// Policy describes how a request failing with a transient error is retried.
+// The delay between two attempts grows exponentially from BaseDelay up to MaxDelay,
+// and it is randomized between half and the full value, to spread the retries of concurrent requests.
+// A Retry-After header sent by the server overrides the computed delay,
+// unless it exceeds MaxDelay, in which case the request is not retried at all.
+// Neither is a request whose context would expire before the delay is over.
+type Policy struct {
+	// MaxAttempts is the number of attempts, including the first one; 1 disables retries
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry
+	BaseDelay time.Duration
+
+	// MaxDelay is the upper bound of the delay between two attempts
+	MaxDelay time.Duration
+
+	// Veto, if set, is called with every response about to be retried, and prevents the retry by returning true.
+	// It lets the caller account for the responses that are not returned, and decide which ones are worth retrying.
+	Veto func(resp *http.Response) bool
+}
+
+// DefaultPolicy returns the Policy used by the API clients when none is provided
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: DefaultMaxAttempts,
+		BaseDelay:   DefaultBaseDelay,
+		MaxDelay:    DefaultMaxDelay,
+	}
+}
+
+// randFloat64 is overridden in tests to remove the jitter
+var randFloat64 = rand.Float64
+
+// Do sends req with httpClient, retrying it on connection errors and transient status codes.
+// Only idempotent requests are retried: the ones with an idempotent method, and the ones marked
+// with an `Idempotency-Key` header, following the net/http convention;
+// a nil `Idempotency-Key` header marks the request without sending the header.
+// Every retry is logged on logger.
+func (p Policy) Do(logger *log.Logger, httpClient *http.Client, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		resp, err := httpClient.Do(attemptReq)
+
+		if attempt >= p.MaxAttempts || !isIdempotent(req) || ctx.Err() != nil {
+			return resp, err
+		}
+		delay, reason, retryable := p.nextDelay(attempt, resp, err)
+		if !retryable || !withinDeadline(ctx, delay) {
+			return resp, err
+		}
+		if resp != nil && p.Veto != nil && p.Veto(resp) {
+			return resp, err
+		}
+
+		if resp != nil {
+			// drain the body to let the connection be reused
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		logger.Printf(
+			"retrying %s %s in %s after %s (attempt %d of %d)",
+			req.Method, req.URL.Redacted(), delay, reason, attempt+1, p.MaxAttempts,
+		)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		if attemptReq, err = cloneRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// nextDelay reports whether the outcome of an attempt is transient, and how long to wait before the next one
+func (p Policy) nextDelay(attempt int, resp *http.Response, err error) (time.Duration, string, bool) {
+	if err != nil {
+		return p.backoff(attempt), err.Error(), true
+	}
+	if !IsTransientStatus(resp.StatusCode) {
+		return 0, "", false
+	}
+
+	reason := fmt.Sprintf("status %d", resp.StatusCode)
+	if retryAfter, ok := ParseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		if retryAfter > p.MaxDelay {
+			return 0, "", false
+		}
+		return retryAfter, reason, true
+	}
+	return p.backoff(attempt), reason, true
+}
+
+// withinDeadline reports whether ctx is still alive after delay, leaving time for another attempt
+func withinDeadline(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return !ok || time.Until(deadline) > delay
+}
+
+// backoff returns the jittered exponential delay after the given attempt
+func (p Policy) backoff(attempt int) time.Duration {
+	delay := p.BaseDelay << (attempt - 1)
+	if delay > p.MaxDelay || delay <= 0 {
+		delay = p.MaxDelay
+	}
+	half := delay / 2
+	return half + time.Duration(randFloat64()*float64(delay-half))
+}
+
+// IsTransientStatus reports whether a response with statusCode is worth retrying
+func IsTransientStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, hasKey := req.Header["Idempotency-Key"]
+	_, hasXKey := req.Header["X-Idempotency-Key"]
+	return hasKey || hasXKey
+}
+
+// ParseRetryAfter parses a Retry-After header, expressed either in seconds or as an HTTP date,
+// into the delay it asks for
+func ParseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// cloneRequest returns a copy of req with a fresh body, to be sent again
+func cloneRequest(req *http.Request) (*http.Request, error) {
+	clone := req.Clone(req.Context())
+	if req.Body == nil || req.GetBody == nil {
+		return clone, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	clone.Body = body
+	return clone, nil
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,276 @@
+package retry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyServer fails with the given status codes before responding 200 OK,
+// it records the bodies it receives
+func flakyServer(t *testing.T, failures []int, retryAfter string, bodies *[]string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		if bodies != nil {
+			bodyBytes, _ := io.ReadAll(r.Body)
+			*bodies = append(*bodies, string(bodyBytes))
+		}
+		if call <= len(failures) {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(failures[call-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	return server, &calls
+}
+
+func testPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    10 * time.Millisecond,
+	}
+}
+
+func TestDo(t *testing.T) {
+	tests := map[string]struct {
+		method     string
+		failures   []int
+		retryAfter string
+		idempotent bool
+
+		expectedStatusCode int
+		expectedCalls      int32
+	}{
+		"should retry transient status codes until success": {
+			method:   http.MethodGet,
+			failures: []int{http.StatusServiceUnavailable, http.StatusBadGateway},
+
+			expectedStatusCode: http.StatusOK,
+			expectedCalls:      3,
+		},
+		"should give up after max attempts": {
+			method:   http.MethodGet,
+			failures: []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+
+			expectedStatusCode: http.StatusGatewayTimeout,
+			expectedCalls:      3,
+		},
+		"should not retry non transient status codes": {
+			method:   http.MethodGet,
+			failures: []int{http.StatusNotFound},
+
+			expectedStatusCode: http.StatusNotFound,
+			expectedCalls:      1,
+		},
+		"should honor a short Retry-After": {
+			method:     http.MethodGet,
+			failures:   []int{http.StatusTooManyRequests},
+			retryAfter: "0",
+
+			expectedStatusCode: http.StatusOK,
+			expectedCalls:      2,
+		},
+		"should not retry if Retry-After exceeds the max delay": {
+			method:     http.MethodGet,
+			failures:   []int{http.StatusTooManyRequests},
+			retryAfter: "2700",
+
+			expectedStatusCode: http.StatusTooManyRequests,
+			expectedCalls:      1,
+		},
+		"should not retry a non idempotent request": {
+			method:   http.MethodPost,
+			failures: []int{http.StatusServiceUnavailable},
+
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedCalls:      1,
+		},
+		"should retry a post marked as idempotent": {
+			method:     http.MethodPost,
+			failures:   []int{http.StatusServiceUnavailable},
+			idempotent: true,
+
+			expectedStatusCode: http.StatusOK,
+			expectedCalls:      2,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server, calls := flakyServer(t, tt.failures, tt.retryAfter, nil)
+			defer server.Close()
+
+			req, _ := http.NewRequest(tt.method, server.URL, nil)
+			if tt.idempotent {
+				req.Header["Idempotency-Key"] = nil
+			}
+			resp, err := testPolicy().Do(log.Default(), http.DefaultClient, req)
+			if err != nil {
+				t.Fatalf("received error %v; want nil", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.expectedStatusCode {
+				t.Errorf("found statusCode=%d; want %d", resp.StatusCode, tt.expectedStatusCode)
+			}
+			if found := calls.Load(); found != tt.expectedCalls {
+				t.Errorf("found %d calls; want %d", found, tt.expectedCalls)
+			}
+		})
+	}
+
+	t.Run("should send the same body on every attempt", func(t *testing.T) {
+		var bodies []string
+		server, _ := flakyServer(t, []int{http.StatusServiceUnavailable}, "", &bodies)
+		defer server.Close()
+
+		req, _ := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader([]byte(`{"text":"hello"}`)))
+		req.Header["Idempotency-Key"] = nil
+		resp, err := testPolicy().Do(log.Default(), http.DefaultClient, req)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		resp.Body.Close()
+
+		expected := []string{`{"text":"hello"}`, `{"text":"hello"}`}
+		if strings.Join(bodies, ",") != strings.Join(expected, ",") {
+			t.Errorf("found bodies %v; want %v", bodies, expected)
+		}
+	})
+
+	t.Run("should retry connection errors and log every retry", func(t *testing.T) {
+		server, _ := flakyServer(t, nil, "", nil)
+		serverURL := server.URL
+		server.Close()
+
+		var logs bytes.Buffer
+		req, _ := http.NewRequest(http.MethodGet, serverURL, nil)
+		_, err := testPolicy().Do(log.New(&logs, "", 0), http.DefaultClient, req)
+		if err == nil {
+			t.Fatalf("received nil error; want a connection error")
+		}
+
+		if found := strings.Count(logs.String(), "retrying GET"); found != 2 {
+			t.Errorf("found %d retry logs; want 2, logs:\n%s", found, logs.String())
+		}
+	})
+
+	t.Run("should stop retrying once the context is done", func(t *testing.T) {
+		server, calls := flakyServer(t, []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}, "", nil)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		_, err := testPolicy().Do(log.Default(), http.DefaultClient, req)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("received error %v; want %v", err, context.Canceled)
+		}
+		if found := calls.Load(); found != 0 {
+			t.Errorf("found %d calls; want 0", found)
+		}
+	})
+
+	t.Run("should not retry if the context expires before the delay is over", func(t *testing.T) {
+		server, calls := flakyServer(t, []int{http.StatusServiceUnavailable}, "1", nil)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		policy := testPolicy()
+		policy.MaxDelay = time.Minute
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := policy.Do(log.Default(), http.DefaultClient, req)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Errorf("found status code %d; want %d", resp.StatusCode, http.StatusServiceUnavailable)
+		}
+		if found := calls.Load(); found != 1 {
+			t.Errorf("found %d calls; want 1", found)
+		}
+	})
+
+	t.Run("should let the veto see every retried response and stop the retries", func(t *testing.T) {
+		failures := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}
+		server, calls := flakyServer(t, failures, "", nil)
+		defer server.Close()
+
+		var vetoed []int
+		policy := testPolicy()
+		policy.Veto = func(resp *http.Response) bool {
+			vetoed = append(vetoed, resp.StatusCode)
+			return resp.StatusCode == http.StatusTooManyRequests
+		}
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := policy.Do(log.Default(), http.DefaultClient, req)
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("found status code %d; want %d", resp.StatusCode, http.StatusTooManyRequests)
+		}
+		if found := calls.Load(); found != 2 {
+			t.Errorf("found %d calls; want 2", found)
+		}
+		if !slices.Equal(vetoed, failures) {
+			t.Errorf("found vetoed status codes %v; want %v", vetoed, failures)
+		}
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	randFloat64 = func() float64 { return 1 }
+	defer func() { randFloat64 = rand.Float64 }()
+
+	policy := Policy{MaxAttempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	expected := []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	}
+	for i, want := range expected {
+		if found := policy.backoff(i + 1); found != want {
+			t.Errorf("backoff(%d) = %s; want %s", i+1, found, want)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if found, ok := ParseRetryAfter("120"); !ok || found != 2*time.Minute {
+		t.Errorf("ParseRetryAfter(120) = %s, %v; want 2m, true", found, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if found, ok := ParseRetryAfter(date); !ok || found <= 59*time.Minute {
+		t.Errorf("ParseRetryAfter(%s) = %s, %v; want about 1h, true", date, found, ok)
+	}
+	if _, ok := ParseRetryAfter("soon"); ok {
+		t.Errorf("ParseRetryAfter(soon) reported ok; want false")
+	}
+}
@@ -0,0 +1,3 @@
+// Package singleflight provides the coalescing of concurrent identical calls shared by the API clients,
+// so that a burst of lookups of the same resource makes a single request to the external API.
+package singleflight
@@ -0,0 +1,87 @@
+package singleflight
+
+import (
+	"context"
+	"sync"
+)
+
+// Group coalesces the concurrent calls sharing a key into a single one, whose result all of them receive.
+// The zero value is ready to use.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+// call is a call in flight, or completed once done is closed
+type call[T any] struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	value  T
+	err    error
+
+	// callers counts every caller of the call, waiters the ones still waiting for its result;
+	// both are guarded by the mutex of the Group
+	callers int
+	waiters int
+}
+
+// Do calls fn once for the concurrent calls sharing key, and returns its result to all of them;
+// shared reports whether the result was returned to more than one caller, which then must not modify it.
+// fn receives the values and the deadline of the context of the first caller, but it is only cancelled
+// once every caller has given up; a caller whose ctx is done returns ctx.Err() straight away.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func(ctx context.Context) (T, error)) (value T, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	c, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		if deadline, ok := ctx.Deadline(); ok {
+			// past the deadline of the first caller the call is not worth retrying anymore
+			callCtx, cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+		}
+		c = &call[T]{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go g.run(callCtx, key, c, fn)
+	}
+	c.callers++
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		// the callers are final once done, since the call is no longer in the group
+		return c.value, c.callers > 1, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		c.waiters--
+		if c.waiters == 0 {
+			// nobody is interested in the result anymore, the next caller starts a new call
+			c.cancel()
+			g.forget(key, c)
+		}
+		var zero T
+		return zero, false, ctx.Err()
+	}
+}
+
+// run calls fn and publishes its result to the callers of c
+func (g *Group[T]) run(ctx context.Context, key string, c *call[T], fn func(ctx context.Context) (T, error)) {
+	defer c.cancel()
+	value, err := fn(ctx)
+
+	g.mu.Lock()
+	g.forget(key, c)
+	c.value, c.err = value, err
+	g.mu.Unlock()
+	close(c.done)
+}
+
+// forget removes c from the calls in flight, unless a new call for key already replaced it
+func (g *Group[T]) forget(key string, c *call[T]) {
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
@@ -0,0 +1,180 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	t.Run("should share a single call among the concurrent callers of a key", func(t *testing.T) {
+		var group Group[string]
+		var calls atomic.Int32
+		release := make(chan struct{})
+		fn := func(ctx context.Context) (string, error) {
+			calls.Add(1)
+			<-release
+			return "pikachu", nil
+		}
+
+		const callers = 20
+		var wg sync.WaitGroup
+		results := make(chan string, callers)
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, shared, err := group.Do(context.Background(), "pikachu", fn)
+				if err != nil || !shared {
+					t.Errorf("found shared=%t, err=%v; want true, nil", shared, err)
+				}
+				results <- value
+			}()
+		}
+		waitForWaiters(t, &group, "pikachu", callers)
+		close(release)
+		wg.Wait()
+		close(results)
+
+		if found := calls.Load(); found != 1 {
+			t.Errorf("found %d calls; want 1", found)
+		}
+		for value := range results {
+			if value != "pikachu" {
+				t.Errorf("found value=%s; want pikachu", value)
+			}
+		}
+	})
+
+	t.Run("should not share the calls of different keys", func(t *testing.T) {
+		var group Group[string]
+		fn := func(ctx context.Context) (string, error) { return "value", nil }
+
+		for _, key := range []string{"pikachu", "raichu"} {
+			if _, shared, err := group.Do(context.Background(), key, fn); err != nil || shared {
+				t.Errorf("found shared=%t, err=%v; want false, nil", shared, err)
+			}
+		}
+	})
+
+	t.Run("should share the error of the call", func(t *testing.T) {
+		var group Group[string]
+		expectedErr := errors.New("upstream failed")
+		_, _, err := group.Do(context.Background(), "pikachu", func(ctx context.Context) (string, error) {
+			return "", expectedErr
+		})
+		if !errors.Is(err, expectedErr) {
+			t.Errorf("received error %v; want %v", err, expectedErr)
+		}
+	})
+
+	t.Run("should keep the call going while some caller waits", func(t *testing.T) {
+		var group Group[string]
+		release := make(chan struct{})
+		fn := func(ctx context.Context) (string, error) {
+			select {
+			case <-release:
+				return "pikachu", nil
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+
+		impatientCtx, cancel := context.WithCancel(context.Background())
+		impatientErr := make(chan error)
+		go func() {
+			_, _, err := group.Do(impatientCtx, "pikachu", fn)
+			impatientErr <- err
+		}()
+		waitForWaiters(t, &group, "pikachu", 1)
+
+		patientResult := make(chan string)
+		go func() {
+			value, _, err := group.Do(context.Background(), "pikachu", fn)
+			if err != nil {
+				t.Errorf("received error %v; want nil", err)
+			}
+			patientResult <- value
+		}()
+		waitForWaiters(t, &group, "pikachu", 2)
+
+		cancel()
+		if err := <-impatientErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("received error %v; want %v", err, context.Canceled)
+		}
+		close(release)
+		if value := <-patientResult; value != "pikachu" {
+			t.Errorf("found value=%s; want pikachu", value)
+		}
+	})
+
+	t.Run("should carry the deadline of the first caller", func(t *testing.T) {
+		var group Group[string]
+		expected := time.Now().Add(time.Minute)
+		ctx, cancel := context.WithDeadline(context.Background(), expected)
+		defer cancel()
+
+		_, _, err := group.Do(ctx, "pikachu", func(ctx context.Context) (string, error) {
+			if found, ok := ctx.Deadline(); !ok || !found.Equal(expected) {
+				t.Errorf("found deadline %v, %t; want %v, true", found, ok, expected)
+			}
+			return "pikachu", nil
+		})
+		if err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+	})
+
+	t.Run("should cancel the call once every caller gave up", func(t *testing.T) {
+		var group Group[string]
+		cancelled := make(chan struct{})
+		fn := func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return "", ctx.Err()
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			group.Do(ctx, "pikachu", fn)
+		}()
+		waitForWaiters(t, &group, "pikachu", 1)
+		cancel()
+		<-done
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("the call was not cancelled")
+		}
+		// the next caller starts a new call instead of receiving the cancellation
+		value, _, err := group.Do(context.Background(), "pikachu", func(ctx context.Context) (string, error) {
+			return "pikachu", nil
+		})
+		if value != "pikachu" || err != nil {
+			t.Errorf("found value=%s, err=%v; want pikachu, nil", value, err)
+		}
+	})
+}
+
+// waitForWaiters waits until n callers are waiting for the call of key
+func waitForWaiters[T any](t *testing.T, group *Group[T], key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		group.mu.Lock()
+		c, ok := group.calls[key]
+		waiting := ok && c.waiters == n
+		group.mu.Unlock()
+		if waiting {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d callers are not waiting for %s", n, key)
+}
@@ -0,0 +1,283 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskEntryExt is the extension of the files holding a DiskStore entry,
+// any other file in the directory is ignored
+const diskEntryExt = ".json"
+
+// errMalformedEntry is returned reading a file that does not hold a DiskStore entry
+var errMalformedEntry = errors.New("malformed cache entry")
+
+// DiskStore is a Store keeping every value in its own file, inside a directory.
+// Being plain files, a populated directory can be shipped along with the service to start with a warm cache.
+// When full, the least recently used files are evicted, reading an entry updates the modification time of its file.
+type DiskStore struct {
+	dir        string
+	maxEntries int
+
+	// mu guards the counters and serializes the writes, so that they are kept consistent with the directory
+	mu        sync.Mutex
+	entries   int
+	evictions uint64
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// diskEntry is the content of a DiskStore file
+type diskEntry struct {
+	Key   string `json:"key"`
+	Value []byte `json:"value"`
+	// ExpiresAt is nil for entries that never expire
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// NewDiskStore returns a DiskStore saving at most maxEntries entries in dir, creating it if needed.
+// A non-positive maxEntries means unbounded.
+func NewDiskStore(dir string, maxEntries int) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create cache directory: %w", err)
+	}
+	entryFiles, err := listEntryFiles(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read cache directory: %w", err)
+	}
+	return &DiskStore{
+		dir:        dir,
+		maxEntries: maxEntries,
+		entries:    len(entryFiles),
+		now:        time.Now,
+	}, nil
+}
+
+func (d *DiskStore) Get(key string) ([]byte, bool, error) {
+	entry, ok, err := d.read(key)
+	if !ok || err != nil {
+		return nil, false, err
+	}
+	if d.maxEntries > 0 {
+		// best effort, failing only makes the entry more likely to be evicted
+		now := d.now()
+		_ = os.Chtimes(d.path(key), now, now)
+	}
+	return entry.Value, true, nil
+}
+
+func (d *DiskStore) Set(key string, value []byte, ttl time.Duration) error {
+	entry := diskEntry{Key: key, Value: value}
+	if ttl > 0 {
+		expiresAt := d.now().Add(ttl)
+		entry.ExpiresAt = &expiresAt
+	}
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so that readers never see a partially written entry
+	tmpFile, err := os.CreateTemp(d.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err := tmpFile.Write(entryBytes); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	path := d.path(key)
+	_, statErr := os.Stat(path)
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return err
+	}
+	if errors.Is(statErr, fs.ErrNotExist) {
+		d.entries++
+	}
+	if d.maxEntries > 0 && d.entries > d.maxEntries {
+		return d.evict(path)
+	}
+	return nil
+}
+
+func (d *DiskStore) Delete(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.remove(d.path(key))
+}
+
+func (d *DiskStore) TTL(key string) (time.Duration, bool, error) {
+	entry, ok, err := d.read(key)
+	if !ok || err != nil {
+		return 0, false, err
+	}
+	if entry.ExpiresAt == nil {
+		return 0, true, nil
+	}
+	return entry.ExpiresAt.Sub(d.now()), true, nil
+}
+
+// Stats returns the number of entries in the directory and the number of evicted ones.
+// Hits and misses are left to the clients of the store.
+func (d *DiskStore) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return Stats{Entries: d.entries, Evictions: d.evictions}
+}
+
+// Prune deletes the files of the expired entries and of the malformed ones.
+// A file that cannot be read or deleted does not stop the pruning, the errors are joined and returned at the end.
+func (d *DiskStore) Prune() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dirEntries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	// the files are counted again, other processes may share the directory
+	d.entries = 0
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), diskEntryExt) {
+			continue
+		}
+		path := filepath.Join(d.dir, dirEntry.Name())
+		entry, err := readDiskEntry(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		d.entries++
+		if errors.Is(err, errMalformedEntry) || err == nil && d.expired(entry) {
+			err = d.remove(path)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// read returns the entry stored for key, deleting it if expired
+func (d *DiskStore) read(key string) (*diskEntry, bool, error) {
+	entry, err := readDiskEntry(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	// guard against hash collisions and misplaced files
+	if entry.Key != key {
+		return nil, false, nil
+	}
+	if d.expired(entry) {
+		return nil, false, d.Delete(key)
+	}
+	return entry, true, nil
+}
+
+// evict removes the least recently used files until the store is within maxEntries,
+// keepPath is the entry just written and is never evicted. d.mu must be held.
+func (d *DiskStore) evict(keepPath string) error {
+	entryFiles, err := listEntryFiles(d.dir)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entryFiles, func(i, j int) bool {
+		return entryFiles[i].modTime.Before(entryFiles[j].modTime)
+	})
+	// resync with the directory, other processes may share it
+	d.entries = len(entryFiles)
+	for _, entryFile := range entryFiles {
+		if d.entries <= d.maxEntries {
+			break
+		}
+		if entryFile.path == keepPath {
+			continue
+		}
+		if err := d.remove(entryFile.path); err != nil {
+			return err
+		}
+		d.evictions++
+	}
+	return nil
+}
+
+// remove deletes the file at path updating the entries counter, d.mu must be held
+func (d *DiskStore) remove(path string) error {
+	err := os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	d.entries--
+	return nil
+}
+
+func (d *DiskStore) expired(entry *diskEntry) bool {
+	return entry.ExpiresAt != nil && !d.now().Before(*entry.ExpiresAt)
+}
+
+// path returns the file of key, hashing it since keys can contain any character
+func (d *DiskStore) path(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(hash[:])+diskEntryExt)
+}
+
+// entryFile is a file holding a DiskStore entry
+type entryFile struct {
+	path    string
+	modTime time.Time
+}
+
+// listEntryFiles returns the entry files in dir, skipping the ones removed while listing
+func listEntryFiles(dir string) ([]entryFile, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var entryFiles []entryFile
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), diskEntryExt) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			continue
+		}
+		entryFiles = append(entryFiles, entryFile{filepath.Join(dir, dirEntry.Name()), info.ModTime()})
+	}
+	return entryFiles, nil
+}
+
+func readDiskEntry(path string) (*diskEntry, error) {
+	entryBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	entry := &diskEntry{}
+	if err := json.Unmarshal(entryBytes, entry); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", errMalformedEntry, path, err)
+	}
+	return entry, nil
+}
@@ -0,0 +1,3 @@
+// Package cache provides the caching primitives used to avoid hitting the external APIs
+// for data that rarely changes.
+package cache
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of the usage counters of a cache
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Entries   int    `json:"entries"`
+}
+
+// LRU is a concurrency safe cache discarding the least recently used entry when full.
+// Every entry expires after the TTL it has been added with, if any.
+type LRU[V any] struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+	stats    Stats
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+	// expiresAt is zero for entries that never expire
+	expiresAt time.Time
+}
+
+// NewLRU returns an empty LRU holding at most capacity entries, a non-positive capacity means unbounded
+func NewLRU[V any](capacity int) *LRU[V] {
+	return &LRU[V]{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get returns the value stored for key, reporting false if it is missing or expired
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return zero, false
+	}
+	entry := elem.Value.(*lruEntry[V])
+	if c.expired(entry) {
+		c.removeElement(elem)
+		c.stats.Misses++
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	c.stats.Hits++
+	return entry.value, true
+}
+
+// Add stores value for key for the given ttl, evicting the least recently used entry if the cache is full.
+// A non-positive ttl means the entry never expires.
+func (c *LRU[V]) Add(key string, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.now().Add(ttl)
+	}
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*lruEntry[V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[V]{key, value, expiresAt})
+	if c.capacity > 0 && c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// TTL returns the remaining lifetime of the entry stored for key, zero if it never expires.
+// It reports false if the entry is missing or expired, without affecting the recency of the entry.
+func (c *LRU[V]) TTL(key string) (time.Duration, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return 0, false
+	}
+	entry := elem.Value.(*lruEntry[V])
+	if c.expired(entry) {
+		return 0, false
+	}
+	if entry.expiresAt.IsZero() {
+		return 0, true
+	}
+	return entry.expiresAt.Sub(c.now()), true
+}
+
+// Remove deletes the entry stored for key, if any
+func (c *LRU[V]) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Stats returns a snapshot of the cache counters
+func (c *LRU[V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = c.order.Len()
+	return stats
+}
+
+func (c *LRU[V]) expired(entry *lruEntry[V]) bool {
+	return !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt)
+}
+
+func (c *LRU[V]) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*lruEntry[V]).key)
+}
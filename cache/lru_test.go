@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("should return the stored value and count hits and misses", func(t *testing.T) {
+		lru := NewLRU[string](2)
+		lru.Add("pikachu", "electric", time.Minute)
+
+		if found, ok := lru.Get("pikachu"); !ok || found != "electric" {
+			t.Errorf("Get(pikachu) = %s, %v; want electric, true", found, ok)
+		}
+		if _, ok := lru.Get("bulbasaur"); ok {
+			t.Errorf("Get(bulbasaur) reported a hit; want a miss")
+		}
+
+		expected := Stats{Hits: 1, Misses: 1, Entries: 1}
+		if found := lru.Stats(); found != expected {
+			t.Errorf("found stats %+v; want %+v", found, expected)
+		}
+	})
+
+	t.Run("should evict the least recently used entry when full", func(t *testing.T) {
+		lru := NewLRU[int](2)
+		lru.Add("bulbasaur", 1, time.Minute)
+		lru.Add("ivysaur", 2, time.Minute)
+		lru.Get("bulbasaur")
+		lru.Add("venusaur", 3, time.Minute)
+
+		if _, ok := lru.Get("ivysaur"); ok {
+			t.Errorf("Get(ivysaur) reported a hit; want it evicted")
+		}
+		for _, key := range []string{"bulbasaur", "venusaur"} {
+			if _, ok := lru.Get(key); !ok {
+				t.Errorf("Get(%s) reported a miss; want a hit", key)
+			}
+		}
+		if found := lru.Stats().Evictions; found != 1 {
+			t.Errorf("found %d evictions; want 1", found)
+		}
+	})
+
+	t.Run("should not return expired entries", func(t *testing.T) {
+		now := time.Now()
+		lru := NewLRU[int](2)
+		lru.now = func() time.Time { return now }
+		lru.Add("mew", 151, time.Minute)
+
+		now = now.Add(time.Minute)
+
+		if _, ok := lru.Get("mew"); ok {
+			t.Errorf("Get(mew) reported a hit; want the entry expired")
+		}
+		if found := lru.Stats().Entries; found != 0 {
+			t.Errorf("found %d entries; want 0", found)
+		}
+	})
+
+	t.Run("should overwrite an existing entry and never expire entries without ttl", func(t *testing.T) {
+		now := time.Now()
+		lru := NewLRU[int](2)
+		lru.now = func() time.Time { return now }
+		lru.Add("mewtwo", 1, time.Minute)
+		lru.Add("mewtwo", 2, time.Minute)
+		lru.Add("ditto", 3, 0)
+
+		now = now.Add(30 * time.Second)
+
+		if found, _ := lru.Get("mewtwo"); found != 2 {
+			t.Errorf("Get(mewtwo) = %d; want 2", found)
+		}
+		if found, ok := lru.TTL("mewtwo"); !ok || found != 30*time.Second {
+			t.Errorf("TTL(mewtwo) = %s, %v; want %s, true", found, ok, 30*time.Second)
+		}
+
+		now = now.Add(24 * time.Hour)
+
+		if found, ok := lru.Get("ditto"); !ok || found != 3 {
+			t.Errorf("Get(ditto) = %d, %v; want 3, true", found, ok)
+		}
+		if found, ok := lru.TTL("ditto"); !ok || found != 0 {
+			t.Errorf("TTL(ditto) = %s, %v; want 0, true", found, ok)
+		}
+	})
+
+	t.Run("should not evict entries when unbounded", func(t *testing.T) {
+		lru := NewLRU[int](0)
+		for i, key := range []string{"charmander", "charmeleon", "charizard"} {
+			lru.Add(key, i, time.Minute)
+		}
+
+		if found := lru.Stats(); found.Entries != 3 || found.Evictions != 0 {
+			t.Errorf("found stats %+v; want 3 entries and no evictions", found)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"time"
+)
+
+// Store is a key-value storage for cached data.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the value stored for key, reporting false if it is missing or expired
+	Get(key string) ([]byte, bool, error)
+
+	// Set stores value for key for the given ttl, a non-positive ttl means the value never expires
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete removes the value stored for key, deleting a missing key is not an error
+	Delete(key string) error
+
+	// TTL returns the remaining lifetime of the value stored for key, zero if it never expires.
+	// It reports false if the value is missing or expired.
+	TTL(key string) (time.Duration, bool, error)
+}
+
+// StatsReporter is implemented by the stores counting their entries and evictions
+type StatsReporter interface {
+	Stats() Stats
+}
+
+// StoreStats returns the entries and evictions of store, zero if it does not report them.
+// Hits and misses are left out, since they are counted by the clients of the store.
+func StoreStats(store Store) Stats {
+	reporter, ok := store.(StatsReporter)
+	if !ok {
+		return Stats{}
+	}
+	stats := reporter.Stats()
+	return Stats{Entries: stats.Entries, Evictions: stats.Evictions}
+}
+
+// MemoryStore is a Store keeping values in a process-local LRU
+type MemoryStore struct {
+	lru *LRU[[]byte]
+}
+
+// NewMemoryStore returns a MemoryStore holding at most maxEntries values,
+// a non-positive maxEntries means unbounded
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{NewLRU[[]byte](maxEntries)}
+}
+
+func (m *MemoryStore) Get(key string) ([]byte, bool, error) {
+	value, ok := m.lru.Get(key)
+	return value, ok, nil
+}
+
+func (m *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	m.lru.Add(key, value, ttl)
+	return nil
+}
+
+func (m *MemoryStore) Delete(key string) error {
+	m.lru.Remove(key)
+	return nil
+}
+
+func (m *MemoryStore) TTL(key string) (time.Duration, bool, error) {
+	ttl, ok := m.lru.TTL(key)
+	return ttl, ok, nil
+}
+
+// Stats returns the counters of the underlying LRU
+func (m *MemoryStore) Stats() Stats {
+	return m.lru.Stats()
+}
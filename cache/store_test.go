@@ -0,0 +1,163 @@
+package cache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStores(t *testing.T) {
+	stores := map[string]func(t *testing.T) Store{
+		"memory": func(t *testing.T) Store {
+			return NewMemoryStore(10)
+		},
+		"disk": func(t *testing.T) Store {
+			diskStore, err := NewDiskStore(t.TempDir(), 0)
+			if err != nil {
+				t.Fatalf("received error %v; want nil", err)
+			}
+			return diskStore
+		},
+	}
+
+	for name, newStore := range stores {
+		t.Run(name+" store should get the values it has set", func(t *testing.T) {
+			store := newStore(t)
+
+			if err := store.Set("pokemon:pikachu", []byte(`{"name":"pikachu"}`), time.Hour); err != nil {
+				t.Errorf("received error %v; want nil", err)
+			}
+			found, ok, err := store.Get("pokemon:pikachu")
+			if err != nil || !ok || !bytes.Equal(found, []byte(`{"name":"pikachu"}`)) {
+				t.Errorf("Get(pokemon:pikachu) = %s, %v, %v; want the stored value", found, ok, err)
+			}
+			ttl, ok, err := store.TTL("pokemon:pikachu")
+			if err != nil || !ok || ttl <= 0 || ttl > time.Hour {
+				t.Errorf("TTL(pokemon:pikachu) = %s, %v, %v; want at most 1h", ttl, ok, err)
+			}
+		})
+
+		t.Run(name+" store should report missing and deleted values", func(t *testing.T) {
+			store := newStore(t)
+
+			if _, ok, err := store.Get("pokemon:missingno"); ok || err != nil {
+				t.Errorf("Get(pokemon:missingno) = %v, %v; want a miss", ok, err)
+			}
+			if err := store.Delete("pokemon:missingno"); err != nil {
+				t.Errorf("Delete(pokemon:missingno) received error %v; want nil", err)
+			}
+
+			store.Set("pokemon:ditto", []byte("ditto"), 0)
+			if ttl, ok, _ := store.TTL("pokemon:ditto"); !ok || ttl != 0 {
+				t.Errorf("TTL(pokemon:ditto) = %s, %v; want 0, true", ttl, ok)
+			}
+			if err := store.Delete("pokemon:ditto"); err != nil {
+				t.Errorf("Delete(pokemon:ditto) received error %v; want nil", err)
+			}
+			if _, ok, _ := store.Get("pokemon:ditto"); ok {
+				t.Errorf("Get(pokemon:ditto) reported a hit; want it deleted")
+			}
+		})
+	}
+}
+
+func TestDiskStore(t *testing.T) {
+	t.Run("should persist values across instances and expire them", func(t *testing.T) {
+		dir := t.TempDir()
+		now := time.Now()
+		first, _ := NewDiskStore(dir, 0)
+		first.now = func() time.Time { return now }
+		first.Set("translation:yoda", []byte("persisted, it is"), time.Minute)
+
+		second, _ := NewDiskStore(dir, 0)
+		second.now = func() time.Time { return now }
+		if found, ok, _ := second.Get("translation:yoda"); !ok || string(found) != "persisted, it is" {
+			t.Errorf("Get(translation:yoda) = %s, %v; want the persisted value", found, ok)
+		}
+
+		now = now.Add(time.Minute)
+		if _, ok, _ := second.Get("translation:yoda"); ok {
+			t.Errorf("Get(translation:yoda) reported a hit; want the value expired")
+		}
+	})
+
+	t.Run("should evict the least recently used entries when full", func(t *testing.T) {
+		dir := t.TempDir()
+		now := time.Now().Add(-time.Hour)
+		store, _ := NewDiskStore(dir, 2)
+		store.now = func() time.Time { return now }
+		for _, key := range []string{"bulbasaur", "ivysaur"} {
+			store.Set(key, []byte(key), 0)
+			os.Chtimes(store.path(key), now, now)
+			now = now.Add(time.Second)
+		}
+		store.Get("bulbasaur")
+		store.Set("venusaur", []byte("venusaur"), 0)
+
+		if _, ok, _ := store.Get("ivysaur"); ok {
+			t.Errorf("Get(ivysaur) reported a hit; want it evicted")
+		}
+		for _, key := range []string{"bulbasaur", "venusaur"} {
+			if _, ok, _ := store.Get(key); !ok {
+				t.Errorf("Get(%s) reported a miss; want a hit", key)
+			}
+		}
+		expectedStats := Stats{Entries: 2, Evictions: 1}
+		if found := store.Stats(); found != expectedStats {
+			t.Errorf("found stats %+v; want %+v", found, expectedStats)
+		}
+
+		restarted, _ := NewDiskStore(dir, 2)
+		if found := restarted.Stats(); found.Entries != 2 {
+			t.Errorf("found %d entries after a restart; want 2", found.Entries)
+		}
+	})
+
+	t.Run("prune should delete malformed entries and keep going", func(t *testing.T) {
+		dir := t.TempDir()
+		now := time.Now()
+		store, _ := NewDiskStore(dir, 0)
+		store.now = func() time.Time { return now }
+		os.WriteFile(filepath.Join(dir, "0-malformed.json"), []byte("{"), 0o644)
+		store.Set("short", []byte("short"), time.Minute)
+		store.Set("long", []byte("long"), time.Hour)
+
+		now = now.Add(time.Minute)
+		if err := store.Prune(); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+
+		files, _ := os.ReadDir(dir)
+		if len(files) != 1 {
+			t.Errorf("found %d files; want only the long entry", len(files))
+		}
+		if found := store.Stats(); found.Entries != 1 {
+			t.Errorf("found %d entries; want 1", found.Entries)
+		}
+	})
+
+	t.Run("prune should delete expired entries and ignore unrelated files", func(t *testing.T) {
+		dir := t.TempDir()
+		now := time.Now()
+		store, _ := NewDiskStore(dir, 0)
+		store.now = func() time.Time { return now }
+		store.Set("short", []byte("short"), time.Minute)
+		store.Set("long", []byte("long"), time.Hour)
+		os.WriteFile(filepath.Join(dir, ".gitkeep"), nil, 0o644)
+
+		now = now.Add(time.Minute)
+		if err := store.Prune(); err != nil {
+			t.Errorf("received error %v; want nil", err)
+		}
+
+		files, _ := os.ReadDir(dir)
+		if len(files) != 2 {
+			t.Errorf("found %d files; want the long entry and .gitkeep", len(files))
+		}
+		if _, ok, _ := store.Get("long"); !ok {
+			t.Errorf("Get(long) reported a miss; want a hit")
+		}
+	})
+}
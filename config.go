@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"malta895/pokedex/apiclients/circuitbreaker"
+	"malta895/pokedex/apiclients/funtranslations"
+	"malta895/pokedex/apiclients/httpclient"
+	"malta895/pokedex/apiclients/pokeapi"
+	"malta895/pokedex/apiclients/retry"
+	"malta895/pokedex/cache"
+	"malta895/pokedex/textnorm"
+	"malta895/pokedex/translationrules"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// userAgentEnv is the env variable holding the User-Agent sent to every upstream API
+const userAgentEnv = "UPSTREAM_USER_AGENT"
+
+// clientConfig holds the settings shared by the API clients,
+// read from the env variables starting with a client specific prefix:
+//
+//   - <PREFIX>_BASE_URL: the base URL of the API, e.g. an internal mirror
+//   - <PREFIX>_TIMEOUT: the timeout of every request, as a Go duration (e.g. `5s`)
+//   - <PREFIX>_HEADERS: extra headers, formatted as `Key: value; Other-Key: other value`
+//   - <PREFIX>_RETRY_MAX_ATTEMPTS: attempts made for a request failing with transient errors, 1 disables retries
+//   - <PREFIX>_RETRY_BASE_DELAY, <PREFIX>_RETRY_MAX_DELAY: bounds of the delay between two attempts
+type clientConfig struct {
+	baseURL     string
+	timeout     time.Duration
+	userAgent   string
+	headers     http.Header
+	retryPolicy retry.Policy
+}
+
+func loadClientConfig(logger *log.Logger, prefix string) clientConfig {
+	config := clientConfig{
+		baseURL:     os.Getenv(prefix + "_BASE_URL"),
+		userAgent:   os.Getenv(userAgentEnv),
+		retryPolicy: retry.DefaultPolicy(),
+	}
+
+	config.timeout, _ = envDuration(logger, prefix+"_TIMEOUT")
+
+	headersEnv := prefix + "_HEADERS"
+	if headers := os.Getenv(headersEnv); headers != "" {
+		parsed, err := parseHeaders(headers)
+		if err != nil {
+			logger.Printf("invalid %s, ignoring it: %s", headersEnv, err)
+		} else {
+			config.headers = parsed
+		}
+	}
+
+	if maxAttempts, ok := envInt(logger, prefix+"_RETRY_MAX_ATTEMPTS"); ok {
+		config.retryPolicy.MaxAttempts = maxAttempts
+	}
+	if baseDelay, ok := envDuration(logger, prefix+"_RETRY_BASE_DELAY"); ok {
+		config.retryPolicy.BaseDelay = baseDelay
+	}
+	if maxDelay, ok := envDuration(logger, prefix+"_RETRY_MAX_DELAY"); ok {
+		config.retryPolicy.MaxDelay = maxDelay
+	}
+
+	return config
+}
+
+// envDuration reads a positive Go duration (e.g. `5s`) from the given env variable,
+// reporting false if it is unset or invalid
+func envDuration(logger *log.Logger, key string) (time.Duration, bool) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, false
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		logger.Printf("invalid %s %q, using the default value", key, value)
+		return 0, false
+	}
+	return parsed, true
+}
+
+// envInt reads a non-negative integer from the given env variable,
+// reporting false if it is unset or invalid
+func envInt(logger *log.Logger, key string) (int, bool) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, false
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		logger.Printf("invalid %s %q, using the default value", key, value)
+		return 0, false
+	}
+	return parsed, true
+}
+
+// envBool reads a boolean (e.g. `true` or `0`) from the given env variable,
+// reporting false if it is unset or invalid
+func envBool(logger *log.Logger, key string) (bool, bool) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, false
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		logger.Printf("invalid %s %q, using the default value", key, value)
+		return false, false
+	}
+	return parsed, true
+}
+
+// parseHeaders parses a list of headers formatted as `Key: value; Other-Key: other value`
+func parseHeaders(raw string) (http.Header, error) {
+	headers := http.Header{}
+	for _, entry := range strings.Split(raw, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		key, value, found := strings.Cut(entry, ":")
+		key = strings.TrimSpace(key)
+		if !found || key == "" {
+			return nil, fmt.Errorf("malformed header %q, want `Key: value`", entry)
+		}
+		headers.Add(key, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// httpOptionsFromEnv maps the env variables starting with prefix to the options of the http client of an API client,
+// see clientConfig
+func httpOptionsFromEnv(logger *log.Logger, prefix string) []httpclient.Option {
+	config := loadClientConfig(logger, prefix)
+
+	opts := []httpclient.Option{
+		httpclient.WithLogger(logger),
+		httpclient.WithRetryPolicy(config.retryPolicy),
+	}
+	if config.baseURL != "" {
+		opts = append(opts, httpclient.WithBaseURL(config.baseURL))
+	}
+	if config.timeout > 0 {
+		opts = append(opts, httpclient.WithTimeout(config.timeout))
+	}
+	if config.userAgent != "" {
+		opts = append(opts, httpclient.WithUserAgent(config.userAgent))
+	}
+	for key, values := range config.headers {
+		for _, value := range values {
+			opts = append(opts, httpclient.WithHeader(key, value))
+		}
+	}
+	return opts
+}
+
+// funtranslationsOptionsFromEnv maps the FUNTRANSLATIONS_* env variables to the funtranslations client options,
+// along with the API secret read from FUNTRANSLATIONS_API_SECRET, or from the file at FUNTRANSLATIONS_API_SECRET_FILE
+func funtranslationsOptionsFromEnv(logger *log.Logger) ([]funtranslations.Option, error) {
+	secret, err := secretFromEnv(logger, "FUNTRANSLATIONS_API_SECRET")
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []funtranslations.Option{
+		funtranslations.WithHTTPOptions(httpOptionsFromEnv(logger, "FUNTRANSLATIONS")...),
+	}
+	if secret != "" {
+		opts = append(opts, funtranslations.WithAPISecret(secret))
+	}
+	return opts, nil
+}
+
+// secretFromEnv reads a secret from the given env variable, or from the file at the path held by key_FILE,
+// e.g. a mounted Docker or Kubernetes secret; the file takes precedence and must be readable
+func secretFromEnv(logger *log.Logger, key string) (string, error) {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		if os.Getenv(key) != "" {
+			logger.Printf("both %s and %s_FILE are set, using the file", key, key)
+		}
+		secret, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading %s_FILE: %w", key, err)
+		}
+		return strings.TrimSpace(string(secret)), nil
+	}
+	return strings.TrimSpace(os.Getenv(key)), nil
+}
+
+const (
+	// funtranslationsRemoteBackend translates with the Fun Translations API
+	funtranslationsRemoteBackend = "remote"
+
+	// funtranslationsOfflineBackend translates locally, e.g. where there is no internet access
+	funtranslationsOfflineBackend = "offline"
+)
+
+// funtranslationsModeFromEnv reads how the descriptions are translated:
+// FUNTRANSLATIONS_BACKEND selects the Fun Translations API (`remote`, the default) or the offline translators (`offline`),
+// and FUNTRANSLATIONS_OFFLINE_FALLBACK makes the offline translators take over when the remote backend fails
+func funtranslationsModeFromEnv(logger *log.Logger) (offline, offlineFallback bool) {
+	switch backend := os.Getenv("FUNTRANSLATIONS_BACKEND"); backend {
+	case "", funtranslationsRemoteBackend:
+	case funtranslationsOfflineBackend:
+		offline = true
+	default:
+		logger.Printf("invalid FUNTRANSLATIONS_BACKEND %q, using the default value", backend)
+	}
+	offlineFallback, _ = envBool(logger, "FUNTRANSLATIONS_OFFLINE_FALLBACK")
+	return offline, offlineFallback && !offline
+}
+
+// breakerSettingsFromEnv maps the <PREFIX>_BREAKER_* env variables to the circuit breaker settings:
+//
+//   - <PREFIX>_BREAKER_FAILURE_THRESHOLD: consecutive failures opening the circuit
+//   - <PREFIX>_BREAKER_COOL_DOWN: time the circuit stays open before a trial call, as a Go duration
+func breakerSettingsFromEnv(logger *log.Logger, prefix string) circuitbreaker.Settings {
+	var settings circuitbreaker.Settings
+	settings.FailureThreshold, _ = envInt(logger, prefix+"_BREAKER_FAILURE_THRESHOLD")
+	settings.CoolDown, _ = envDuration(logger, prefix+"_BREAKER_COOL_DOWN")
+	return settings
+}
+
+// defaultPokeAPICacheSize is the number of PokeAPI lookups kept by the in-memory cache
+const defaultPokeAPICacheSize = 1000
+
+// cacheStoresFromEnv returns the stores backing the API clients caches:
+// if CACHE_DIR is set the translations are kept in that directory and the PokeAPI lookups in its pokeapi subdirectory,
+// otherwise each client gets its own in-memory store.
+// POKEAPI_CACHE_SIZE bounds the PokeAPI store either way, translations are never evicted.
+// The deprecated FUNTRANSLATIONS_CACHE_FILE is still honoured when CACHE_DIR is not set,
+// persisting the translations to that single file.
+// A nil pokeapiStore means the PokeAPI cache has been disabled by setting POKEAPI_CACHE_SIZE to 0.
+func cacheStoresFromEnv(logger *log.Logger) (pokeapiStore, funtranslationsStore cache.Store, err error) {
+	pokeapiCacheSize := defaultPokeAPICacheSize
+	if size, ok := envInt(logger, "POKEAPI_CACHE_SIZE"); ok {
+		pokeapiCacheSize = size
+	}
+
+	cacheFile := os.Getenv("FUNTRANSLATIONS_CACHE_FILE")
+	if cacheFile != "" {
+		logger.Printf("FUNTRANSLATIONS_CACHE_FILE is deprecated, set CACHE_DIR instead")
+	}
+
+	cacheDir := os.Getenv("CACHE_DIR")
+	if cacheDir == "" {
+		if pokeapiCacheSize != 0 {
+			pokeapiStore = cache.NewMemoryStore(pokeapiCacheSize)
+		}
+		// translations are few, given the rate limits, and they never change
+		funtranslationsStore = cache.NewMemoryStore(0)
+		if cacheFile != "" {
+			fileStore, err := funtranslations.NewFileStore(cacheFile)
+			if err != nil {
+				return nil, nil, err
+			}
+			funtranslationsStore = fileStore
+		}
+		return pokeapiStore, funtranslationsStore, nil
+	}
+
+	if cacheFile != "" {
+		logger.Printf("ignoring FUNTRANSLATIONS_CACHE_FILE since CACHE_DIR is set")
+	}
+	translationsDiskStore, err := prunedDiskStore(logger, cacheDir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	funtranslationsStore = translationsDiskStore
+	if pokeapiCacheSize != 0 {
+		pokeapiDiskStore, err := prunedDiskStore(logger, filepath.Join(cacheDir, "pokeapi"), pokeapiCacheSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		pokeapiStore = pokeapiDiskStore
+	}
+	return pokeapiStore, funtranslationsStore, nil
+}
+
+// prunedDiskStore returns a disk store in dir without its expired entries,
+// pruning failures are only logged since they leave a working store
+func prunedDiskStore(logger *log.Logger, dir string, maxEntries int) (*cache.DiskStore, error) {
+	diskStore, err := cache.NewDiskStore(dir, maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	if err := diskStore.Prune(); err != nil {
+		logger.Printf("error pruning expired cache entries in %s: %v", dir, err)
+	}
+	return diskStore, nil
+}
+
+// pokeAPICacheOptionsFromEnv maps the POKEAPI_CACHE_*_TTL env variables to the pokeapi cache options
+func pokeAPICacheOptionsFromEnv(logger *log.Logger) pokeapi.CacheOptions {
+	var opts pokeapi.CacheOptions
+	opts.TTL, _ = envDuration(logger, "POKEAPI_CACHE_TTL")
+	opts.NotFoundTTL, _ = envDuration(logger, "POKEAPI_CACHE_NOT_FOUND_TTL")
+	return opts
+}
+
+// normalizerFromEnv maps the DESCRIPTION_* env variables to the normalizer of the descriptions,
+// nil when the normalization is disabled
+func normalizerFromEnv(logger *log.Logger) *textnorm.Normalizer {
+	if enabled, ok := envBool(logger, "DESCRIPTION_NORMALIZATION"); ok && !enabled {
+		return nil
+	}
+	var opts []textnorm.Option
+	if fixCasing, _ := envBool(logger, "DESCRIPTION_FIX_CASING"); fixCasing {
+		opts = append(opts, textnorm.WithCasingFixes())
+	}
+	return textnorm.New(opts...)
+}
+
+// batchConcurrencyFromEnv reads the number of pokemon of a batch retrieved at the same time from BATCH_CONCURRENCY,
+// the zero value selects the default
+func batchConcurrencyFromEnv(logger *log.Logger) int {
+	concurrency, _ := envInt(logger, "BATCH_CONCURRENCY")
+	return concurrency
+}
+
+// defaultSearchRefreshInterval is the period of the search index refreshes, new species are rare
+const defaultSearchRefreshInterval = 24 * time.Hour
+
+// searchRefreshIntervalFromEnv reads the period of the search index refreshes from SEARCH_INDEX_REFRESH_INTERVAL
+func searchRefreshIntervalFromEnv(logger *log.Logger) time.Duration {
+	if interval, ok := envDuration(logger, "SEARCH_INDEX_REFRESH_INTERVAL"); ok {
+		return interval
+	}
+	return defaultSearchRefreshInterval
+}
+
+// strictTranslationsFromEnv reads from TRANSLATION_STRICT whether the failed translations are an error by default
+func strictTranslationsFromEnv(logger *log.Logger) bool {
+	strict, _ := envBool(logger, "TRANSLATION_STRICT")
+	return strict
+}
+
+// defaultTranslationRulesReloadInterval is the period of the checks for changes of the translation rules file
+const defaultTranslationRulesReloadInterval = 30 * time.Second
+
+// translationRulesFromEnv returns the engine selecting the translators, loading the rules of TRANSLATION_RULES_FILE
+// if set, along with the period of the checks for its changes, read from TRANSLATION_RULES_RELOAD_INTERVAL.
+// An invalid rules file is an error, so that it is fixed before the service starts.
+func translationRulesFromEnv(logger *log.Logger) (engine *translationrules.Engine, path string, reloadInterval time.Duration, err error) {
+	engine = translationrules.NewEngine(logger, funtranslations.SupportedTranslators())
+	reloadInterval = defaultTranslationRulesReloadInterval
+	if interval, ok := envDuration(logger, "TRANSLATION_RULES_RELOAD_INTERVAL"); ok {
+		reloadInterval = interval
+	}
+
+	path = os.Getenv("TRANSLATION_RULES_FILE")
+	if path == "" {
+		return engine, "", reloadInterval, nil
+	}
+	if err := engine.LoadFile(path); err != nil {
+		return nil, "", 0, fmt.Errorf("loading the translation rules file %s: %w", path, err)
+	}
+	return engine, path, reloadInterval, nil
+}
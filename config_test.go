@@ -0,0 +1,447 @@
+package main
+
+import (
+	"errors"
+	"log"
+	"malta895/pokedex/apiclients/funtranslations"
+	"malta895/pokedex/apiclients/retry"
+	"malta895/pokedex/cache"
+	"malta895/pokedex/translationrules"
+	"malta895/pokedex/types"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHeaders(t *testing.T) {
+	tests := map[string]struct {
+		raw string
+
+		expectedHeaders http.Header
+		expectError     bool
+	}{
+		"should parse a single header": {
+			raw:             "X-Api-Key: secret",
+			expectedHeaders: http.Header{"X-Api-Key": {"secret"}},
+		},
+		"should parse multiple headers ignoring blanks": {
+			raw: "X-Api-Key: secret; ;x-tenant:pokedex; X-Tenant: other",
+			expectedHeaders: http.Header{
+				"X-Api-Key": {"secret"},
+				"X-Tenant":  {"pokedex", "other"},
+			},
+		},
+		"should keep colons in the header value": {
+			raw:             "Forwarded: for=127.0.0.1:3000",
+			expectedHeaders: http.Header{"Forwarded": {"for=127.0.0.1:3000"}},
+		},
+		"should fail if a header has no value separator": {
+			raw:         "X-Api-Key secret",
+			expectError: true,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			found, err := parseHeaders(tt.raw)
+			if (err != nil) != tt.expectError {
+				t.Errorf("received error %v; want error=%v", err, tt.expectError)
+			}
+			if !tt.expectError && !reflect.DeepEqual(found, tt.expectedHeaders) {
+				t.Errorf("parseHeaders(%s) = %v; want %v", tt.raw, found, tt.expectedHeaders)
+			}
+		})
+	}
+}
+
+func TestLoadClientConfig(t *testing.T) {
+	t.Run("should read the client settings from the prefixed env variables", func(t *testing.T) {
+		t.Setenv("POKEAPI_BASE_URL", "http://pokeapi.internal/api/v2")
+		t.Setenv("POKEAPI_TIMEOUT", "3s")
+		t.Setenv("POKEAPI_HEADERS", "X-Tenant: pokedex")
+		t.Setenv("POKEAPI_RETRY_MAX_ATTEMPTS", "5")
+		t.Setenv(userAgentEnv, "pokedex/1.0")
+
+		found := loadClientConfig(log.Default(), "POKEAPI")
+
+		expected := clientConfig{
+			baseURL:   "http://pokeapi.internal/api/v2",
+			timeout:   3 * time.Second,
+			userAgent: "pokedex/1.0",
+			headers:   http.Header{"X-Tenant": {"pokedex"}},
+			retryPolicy: retry.Policy{
+				MaxAttempts: 5,
+				BaseDelay:   retry.DefaultBaseDelay,
+				MaxDelay:    retry.DefaultMaxDelay,
+			},
+		}
+		if !reflect.DeepEqual(found, expected) {
+			t.Errorf("loadClientConfig(POKEAPI) = %#v; want %#v", found, expected)
+		}
+	})
+
+	t.Run("should ignore an invalid timeout", func(t *testing.T) {
+		t.Setenv("FUNTRANSLATIONS_TIMEOUT", "soon")
+
+		found := loadClientConfig(log.Default(), "FUNTRANSLATIONS")
+
+		if found.timeout != 0 {
+			t.Errorf("found timeout %s; want 0", found.timeout)
+		}
+	})
+}
+
+func TestCacheStoresFromEnv(t *testing.T) {
+	t.Run("should default to separate in-memory stores", func(t *testing.T) {
+		pokeapiStore, funtranslationsStore, err := cacheStoresFromEnv(log.Default())
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		if _, ok := pokeapiStore.(*cache.MemoryStore); !ok {
+			t.Errorf("found pokeapi store %T; want *cache.MemoryStore", pokeapiStore)
+		}
+		if _, ok := funtranslationsStore.(*cache.MemoryStore); !ok {
+			t.Errorf("found funtranslations store %T; want *cache.MemoryStore", funtranslationsStore)
+		}
+	})
+
+	t.Run("should use disk stores if CACHE_DIR is set", func(t *testing.T) {
+		t.Setenv("CACHE_DIR", t.TempDir())
+
+		pokeapiStore, funtranslationsStore, err := cacheStoresFromEnv(log.Default())
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		if _, ok := pokeapiStore.(*cache.DiskStore); !ok {
+			t.Errorf("found pokeapi store %T; want *cache.DiskStore", pokeapiStore)
+		}
+		if _, ok := funtranslationsStore.(*cache.DiskStore); !ok {
+			t.Errorf("found funtranslations store %T; want *cache.DiskStore", funtranslationsStore)
+		}
+		if pokeapiStore == funtranslationsStore {
+			t.Errorf("found the same store; want the PokeAPI lookups apart from the translations")
+		}
+	})
+
+	t.Run("should bound the pokeapi disk store by POKEAPI_CACHE_SIZE", func(t *testing.T) {
+		t.Setenv("CACHE_DIR", t.TempDir())
+		t.Setenv("POKEAPI_CACHE_SIZE", "1")
+
+		pokeapiStore, funtranslationsStore, err := cacheStoresFromEnv(log.Default())
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		for _, name := range []string{"pikachu", "ditto"} {
+			pokeapiStore.Set("pokemon:"+name, []byte(name), time.Hour)
+			funtranslationsStore.Set("funtranslations:yoda:"+name, []byte(name), 0)
+		}
+		if found := cache.StoreStats(pokeapiStore); found.Entries != 1 || found.Evictions != 1 {
+			t.Errorf("found pokeapi store stats %+v; want 1 entry and 1 eviction", found)
+		}
+		if found := cache.StoreStats(funtranslationsStore); found.Entries != 2 || found.Evictions != 0 {
+			t.Errorf("found funtranslations store stats %+v; want 2 entries and no eviction", found)
+		}
+	})
+
+	t.Run("should persist the translations to FUNTRANSLATIONS_CACHE_FILE", func(t *testing.T) {
+		t.Setenv("FUNTRANSLATIONS_CACHE_FILE", filepath.Join(t.TempDir(), "translations.json"))
+
+		_, funtranslationsStore, err := cacheStoresFromEnv(log.Default())
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		if _, ok := funtranslationsStore.(*funtranslations.FileStore); !ok {
+			t.Errorf("found funtranslations store %T; want *funtranslations.FileStore", funtranslationsStore)
+		}
+	})
+
+	t.Run("should prefer CACHE_DIR to FUNTRANSLATIONS_CACHE_FILE", func(t *testing.T) {
+		t.Setenv("CACHE_DIR", t.TempDir())
+		t.Setenv("FUNTRANSLATIONS_CACHE_FILE", filepath.Join(t.TempDir(), "translations.json"))
+
+		_, funtranslationsStore, err := cacheStoresFromEnv(log.Default())
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		if _, ok := funtranslationsStore.(*cache.DiskStore); !ok {
+			t.Errorf("found funtranslations store %T; want *cache.DiskStore", funtranslationsStore)
+		}
+	})
+
+	t.Run("should disable the pokeapi cache if its size is 0", func(t *testing.T) {
+		t.Setenv("POKEAPI_CACHE_SIZE", "0")
+
+		pokeapiStore, _, err := cacheStoresFromEnv(log.Default())
+		if err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+
+		if pokeapiStore != nil {
+			t.Errorf("found pokeapi store %T; want nil", pokeapiStore)
+		}
+	})
+}
+
+func TestNormalizerFromEnv(t *testing.T) {
+	text := "this POKéMON\nis rare"
+	tests := map[string]struct {
+		env map[string]string
+
+		expectedNil  bool
+		expectedText string
+	}{
+		"should clean up whitespace by default": {
+			expectedText: "this POKéMON is rare",
+		},
+		"should fix casing if enabled": {
+			env: map[string]string{"DESCRIPTION_FIX_CASING": "true"},
+
+			expectedText: "this Pokémon is rare",
+		},
+		"should disable the normalization": {
+			env: map[string]string{"DESCRIPTION_NORMALIZATION": "false", "DESCRIPTION_FIX_CASING": "true"},
+
+			expectedNil: true,
+		},
+		"should ignore invalid values": {
+			env: map[string]string{"DESCRIPTION_NORMALIZATION": "maybe"},
+
+			expectedText: "this POKéMON is rare",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			found := normalizerFromEnv(log.Default())
+			if tt.expectedNil {
+				if found != nil {
+					t.Errorf("found normalizer %+v; want nil", found)
+				}
+				return
+			}
+			if found == nil {
+				t.Fatalf("found nil normalizer; want one")
+			}
+			if normalized := found.Normalize(text); normalized != tt.expectedText {
+				t.Errorf("Normalize(%q) = %q; want %q", text, normalized, tt.expectedText)
+			}
+		})
+	}
+}
+
+func TestSearchRefreshIntervalFromEnv(t *testing.T) {
+	tests := map[string]struct {
+		value string
+
+		expected time.Duration
+	}{
+		"should default to a day":       {expected: 24 * time.Hour},
+		"should read the interval":      {value: "1h", expected: time.Hour},
+		"should ignore invalid values":  {value: "daily", expected: 24 * time.Hour},
+		"should ignore negative values": {value: "-1h", expected: 24 * time.Hour},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("SEARCH_INDEX_REFRESH_INTERVAL", tt.value)
+			if found := searchRefreshIntervalFromEnv(log.Default()); found != tt.expected {
+				t.Errorf("found interval=%s; want %s", found, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTranslationRulesFromEnv(t *testing.T) {
+	dir := t.TempDir()
+	validPath := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(validPath, []byte(`{"default": "yoda"}`), 0o600); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+	invalidPath := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalidPath, []byte(`{"default": "elvish"}`), 0o600); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+
+	tests := map[string]struct {
+		path     string
+		interval string
+
+		expectedErr        error
+		expectedTranslator string
+		expectedInterval   time.Duration
+	}{
+		"should default to the built-in rules": {
+			expectedTranslator: funtranslations.TranslatorShakespeare,
+			expectedInterval:   30 * time.Second,
+		},
+		"should load the rules file": {
+			path:     validPath,
+			interval: "1m",
+
+			expectedTranslator: funtranslations.TranslatorYoda,
+			expectedInterval:   time.Minute,
+		},
+		"should fail on an invalid rules file": {
+			path:        invalidPath,
+			expectedErr: translationrules.ErrInvalidRules,
+		},
+		"should fail on a missing rules file": {
+			path:        filepath.Join(dir, "missing.json"),
+			expectedErr: os.ErrNotExist,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("TRANSLATION_RULES_FILE", tt.path)
+			t.Setenv("TRANSLATION_RULES_RELOAD_INTERVAL", tt.interval)
+			engine, path, interval, err := translationRulesFromEnv(log.Default())
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("received error %v; want %v", err, tt.expectedErr)
+			}
+			if err != nil {
+				return
+			}
+			if path != tt.path {
+				t.Errorf("found path=%s; want %s", path, tt.path)
+			}
+			if interval != tt.expectedInterval {
+				t.Errorf("found interval=%s; want %s", interval, tt.expectedInterval)
+			}
+			if found := engine.Select(&types.Pokemon{Habitat: "forest"}).Translator; found != tt.expectedTranslator {
+				t.Errorf("found translator=%s; want %s", found, tt.expectedTranslator)
+			}
+		})
+	}
+}
+
+func TestFuntranslationsModeFromEnv(t *testing.T) {
+	tests := map[string]struct {
+		backend  string
+		fallback string
+
+		expectedOffline         bool
+		expectedOfflineFallback bool
+	}{
+		"should default to the remote backend": {},
+		"should select the offline backend": {
+			backend: "offline",
+
+			expectedOffline: true,
+		},
+		"should enable the offline fallback": {
+			backend:  "remote",
+			fallback: "true",
+
+			expectedOfflineFallback: true,
+		},
+		"should not fall back from the offline backend": {
+			backend:  "offline",
+			fallback: "true",
+
+			expectedOffline: true,
+		},
+		"should ignore invalid backends": {
+			backend: "carrier-pigeon",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("FUNTRANSLATIONS_BACKEND", tt.backend)
+			t.Setenv("FUNTRANSLATIONS_OFFLINE_FALLBACK", tt.fallback)
+			offline, offlineFallback := funtranslationsModeFromEnv(log.Default())
+			if offline != tt.expectedOffline {
+				t.Errorf("found offline=%t; want %t", offline, tt.expectedOffline)
+			}
+			if offlineFallback != tt.expectedOfflineFallback {
+				t.Errorf("found offlineFallback=%t; want %t", offlineFallback, tt.expectedOfflineFallback)
+			}
+		})
+	}
+}
+
+func TestSecretFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("file-secret\n"), 0o600); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+
+	tests := map[string]struct {
+		value string
+		file  string
+
+		expected    string
+		expectedErr error
+	}{
+		"should default to no secret": {},
+		"should read the secret from the env": {
+			value: "env-secret",
+
+			expected: "env-secret",
+		},
+		"should read the secret from the file, trimming the trailing newline": {
+			file: path,
+
+			expected: "file-secret",
+		},
+		"should prefer the file": {
+			value: "env-secret",
+			file:  path,
+
+			expected: "file-secret",
+		},
+		"should fail on a missing file": {
+			file: filepath.Join(t.TempDir(), "missing"),
+
+			expectedErr: os.ErrNotExist,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("FUNTRANSLATIONS_API_SECRET", tt.value)
+			t.Setenv("FUNTRANSLATIONS_API_SECRET_FILE", tt.file)
+			found, err := secretFromEnv(log.Default(), "FUNTRANSLATIONS_API_SECRET")
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("received error %v; want %v", err, tt.expectedErr)
+			}
+			if found != tt.expected {
+				t.Errorf("found secret=%q; want %q", found, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStrictTranslationsFromEnv(t *testing.T) {
+	tests := map[string]struct {
+		value string
+
+		expected bool
+	}{
+		"should default to lenient translations": {},
+		"should enable the strict translations":  {value: "true", expected: true},
+		"should ignore invalid values":           {value: "always"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("TRANSLATION_STRICT", tt.value)
+			if found := strictTranslationsFromEnv(log.Default()); found != tt.expected {
+				t.Errorf("found strict=%t; want %t", found, tt.expected)
+			}
+		})
+	}
+}
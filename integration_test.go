@@ -40,22 +40,54 @@ func TestIntegration(t *testing.T) {
 
 				expectedResp: `{
 				"name": "mewtwo",
-				"description": "It was created by\na scientist after\nyears of horrific\fgene splicing and\nDNA engineering\nexperiments.",
+				"description": "It was created by a scientist after years of horrific gene splicing and DNA engineering experiments.",
+				"language": "en",
+				"version": "red",
 				"habitat": "rare",
-				"isLegendary": true
+				"isLegendary": true,
+				"generation": "generation-i",
+				"types": ["psychic"],
+				"abilities": [
+					{"name": "pressure", "isHidden": false},
+					{"name": "unnerve", "isHidden": true}
+				],
+				"baseStats": {
+					"hp": 106,
+					"attack": 110,
+					"defense": 90,
+					"special-attack": 154,
+					"special-defense": 90,
+					"speed": 130
+				},
+				"height": 20,
+				"weight": 1220,
+				"baseExperience": 340
 			}`,
 				expectedStatusCode: http.StatusOK,
 			},
 			"should respond 404 Not Found with nonExistingPokemon": {
-				pokemonName:        "nonExistingPokemon",
-				expectedResp:       "Not Found",
+				pokemonName: "nonExistingPokemon",
+				expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/nonExistingPokemon",
+				"requestId": "integration-test"
+			}`,
 				expectedStatusCode: http.StatusNotFound,
 			},
 		}
 
 		for name, tt := range testCases {
 			t.Run(name, func(t *testing.T) {
-				resp, err := http.Get(fmt.Sprintf("http://localhost:5000/pokemon/%s", tt.pokemonName))
+				req, err := http.NewRequest("GET", fmt.Sprintf("http://localhost:5000/pokemon/%s", tt.pokemonName), nil)
+				if err != nil {
+					t.Errorf("error building request: %s", err)
+					return
+				}
+				req.Header.Set("X-Request-ID", "integration-test")
+				resp, err := http.DefaultClient.Do(req)
 				if err != nil {
 					t.Errorf("error making request to server: %s", err)
 					return
@@ -71,9 +103,13 @@ func TestIntegration(t *testing.T) {
 				}
 				foundResp := string(respBytes)
 				if json.Valid([]byte(tt.expectedResp)) {
+					expectedContentType := "application/json"
+					if tt.expectedStatusCode != http.StatusOK {
+						expectedContentType = "application/problem+json"
+					}
 					contentType := resp.Header.Get("Content-Type")
-					if contentType != "application/json" {
-						t.Errorf("found response contentType=%s; want application/json", contentType)
+					if contentType != expectedContentType {
+						t.Errorf("found response contentType=%s; want %s", contentType, expectedContentType)
 					}
 					bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
 					if err != nil {
@@ -105,21 +141,53 @@ func TestIntegration(t *testing.T) {
 				expectedResp: `{
 				"name": "mewtwo",
 				"description": "Created by a scientist after years of horrific gene splicing and dna engineering experiments,  it was.",
+				"language": "en",
+				"version": "red",
 				"habitat": "rare",
-				"isLegendary": true
+				"isLegendary": true,
+				"generation": "generation-i",
+				"types": ["psychic"],
+				"abilities": [
+					{"name": "pressure", "isHidden": false},
+					{"name": "unnerve", "isHidden": true}
+				],
+				"baseStats": {
+					"hp": 106,
+					"attack": 110,
+					"defense": 90,
+					"special-attack": 154,
+					"special-defense": 90,
+					"speed": 130
+				},
+				"height": 20,
+				"weight": 1220,
+				"baseExperience": 340
 			}`,
 				expectedStatusCode: http.StatusOK,
 			},
 			"should respond 404 Not Found with nonExistingPokemon": {
-				pokemonName:        "nonExistingPokemon",
-				expectedResp:       "Not Found",
+				pokemonName: "nonExistingPokemon",
+				expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/translated/nonExistingPokemon",
+				"requestId": "integration-test"
+			}`,
 				expectedStatusCode: http.StatusNotFound,
 			},
 		}
 
 		for name, tt := range testCases {
 			t.Run(name, func(t *testing.T) {
-				resp, err := http.Get(fmt.Sprintf("http://localhost:5000/pokemon/translated/%s", tt.pokemonName))
+				req, err := http.NewRequest("GET", fmt.Sprintf("http://localhost:5000/pokemon/translated/%s", tt.pokemonName), nil)
+				if err != nil {
+					t.Errorf("error building request: %s", err)
+					return
+				}
+				req.Header.Set("X-Request-ID", "integration-test")
+				resp, err := http.DefaultClient.Do(req)
 				if err != nil {
 					t.Errorf("error making request to server: %s", err)
 					return
@@ -135,9 +203,13 @@ func TestIntegration(t *testing.T) {
 				}
 				foundResp := string(respBytes)
 				if json.Valid([]byte(tt.expectedResp)) {
+					expectedContentType := "application/json"
+					if tt.expectedStatusCode != http.StatusOK {
+						expectedContentType = "application/problem+json"
+					}
 					contentType := resp.Header.Get("Content-Type")
-					if contentType != "application/json" {
-						t.Errorf("found response contentType=%s; want application/json", contentType)
+					if contentType != expectedContentType {
+						t.Errorf("found response contentType=%s; want %s", contentType, expectedContentType)
 					}
 					bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
 					if err != nil {
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"malta895/pokedex/apiclients/circuitbreaker"
 	"malta895/pokedex/apiclients/funtranslations"
 	"malta895/pokedex/apiclients/pokeapi"
+	"malta895/pokedex/cache"
 	"malta895/pokedex/pokemonmux"
+	"malta895/pokedex/search"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -21,17 +25,136 @@ func main() {
 		logger.Printf("HTTP_PORT not set, defaulting to %s", httpPort)
 	}
 
-	pokeapiClient := pokeapi.NewClient()
-	funtranslationsClient := funtranslations.NewClient()
-	pokemonMux := pokemonmux.New(
-		logger,
-		pokeapiClient,
-		funtranslationsClient,
-	)
+	pokeapiStore, funtranslationsStore, err := cacheStoresFromEnv(logger)
+	if err != nil {
+		logger.Fatalf("Error setting up the cache: %s", err)
+	}
+
+	pokeapiBreaker := circuitbreaker.New("pokeapi", logger, breakerSettingsFromEnv(logger, "POKEAPI"))
+	// concurrent identical lookups share a single upstream call, e.g. for a trending pokemon
+	pokeapiClient := pokeapi.NewSingleflightClient(pokeapi.NewCircuitBreakerClient(
+		pokeapi.NewClient(httpOptionsFromEnv(logger, "POKEAPI")...),
+		pokeapiBreaker,
+	))
+	cacheStats := map[string]func() cache.Stats{}
+	if pokeapiStore != nil {
+		cachingClient := pokeapi.NewCachingClient(
+			logger,
+			pokeapiClient,
+			pokeapiStore,
+			pokeAPICacheOptionsFromEnv(logger),
+		)
+		defer func() {
+			logger.Printf("pokeapi cache stats: %+v", cachingClient.Stats())
+		}()
+		cacheStats["pokeapi"] = cachingClient.Stats
+		pokeapiClient = cachingClient
+	}
+
+	breakers := []*circuitbreaker.Breaker{pokeapiBreaker}
+	var funtranslationsClient funtranslations.Client
+	// funtranslationsQuota is nil for the offline translations
+	var funtranslationsQuota *funtranslations.QuotaTracker
+	offline, offlineFallback := funtranslationsModeFromEnv(logger)
+	if offline {
+		logger.Printf("translating the descriptions offline")
+		funtranslationsClient = funtranslations.NewOfflineClient()
+	} else {
+		funtranslationsOpts, err := funtranslationsOptionsFromEnv(logger)
+		if err != nil {
+			logger.Fatalf("Error configuring the Fun Translations client: %s", err)
+		}
+		funtranslationsQuota = funtranslations.NewQuotaTracker()
+		funtranslationsOpts = append(funtranslationsOpts, funtranslations.WithQuotaTracker(funtranslationsQuota))
+
+		funtranslationsBreaker := circuitbreaker.New(
+			"funtranslations",
+			logger,
+			breakerSettingsFromEnv(logger, "FUNTRANSLATIONS"),
+		)
+		breakers = append(breakers, funtranslationsBreaker)
+		cachingClient := funtranslations.NewCachingClient(
+			logger,
+			funtranslations.NewSingleflightClient(funtranslations.NewCircuitBreakerClient(
+				funtranslations.NewClient(funtranslationsOpts...),
+				funtranslationsBreaker,
+			)),
+			funtranslationsStore,
+		)
+		defer func() {
+			logger.Printf("funtranslations cache stats: %+v", cachingClient.Stats())
+		}()
+		cacheStats["funtranslations"] = cachingClient.Stats
+		funtranslationsClient = cachingClient
+
+		// the offline translations are not cached, so that the remote ones replace them once it recovers
+		if offlineFallback {
+			funtranslationsClient = funtranslations.NewFallbackClient(
+				logger,
+				funtranslationsClient,
+				funtranslations.NewOfflineClient(),
+			)
+		}
+	}
+
+	// baseCtx is the parent of every request context, cancelling it
+	// aborts the upstream calls of the requests still in flight
+	baseCtx, cancelBaseCtx := context.WithCancel(context.Background())
+	defer cancelBaseCtx()
+
+	searchIndex := search.NewIndex(logger, func(ctx context.Context) ([]string, error) {
+		return pokeapi.SpeciesNames(ctx, pokeapiClient, pokeapi.DefaultListPageSize)
+	})
+	go searchIndex.RefreshEvery(baseCtx, searchRefreshIntervalFromEnv(logger))
+
+	translationRules, translationRulesPath, reloadInterval, err := translationRulesFromEnv(logger)
+	if err != nil {
+		logger.Fatalf("Error setting up the translation rules: %s", err)
+	}
+	if translationRulesPath != "" {
+		go translationRules.WatchFile(baseCtx, translationRulesPath, reloadInterval)
+	}
+
+	muxOpts := []pokemonmux.Option{
+		pokemonmux.WithNormalizer(normalizerFromEnv(logger)),
+		pokemonmux.WithSearchIndex(searchIndex),
+		pokemonmux.WithBatchConcurrency(batchConcurrencyFromEnv(logger)),
+		pokemonmux.WithTranslationRules(translationRules),
+		pokemonmux.WithStrictTranslations(strictTranslationsFromEnv(logger)),
+		pokemonmux.WithStatusReporter("circuitBreakers", func() any {
+			statuses := make([]circuitbreaker.Status, 0, len(breakers))
+			for _, breaker := range breakers {
+				statuses = append(statuses, breaker.Status())
+			}
+			return statuses
+		}),
+		pokemonmux.WithStatusReporter("caches", func() any {
+			stats := make(map[string]cache.Stats, len(cacheStats))
+			for name, statsOf := range cacheStats {
+				stats[name] = statsOf()
+			}
+			return stats
+		}),
+		pokemonmux.WithStatusReporter("searchIndex", func() any {
+			return searchIndex.Status()
+		}),
+		pokemonmux.WithStatusReporter("translationRules", func() any {
+			return translationRules.Status()
+		}),
+	}
+	if funtranslationsQuota != nil {
+		muxOpts = append(muxOpts, pokemonmux.WithStatusReporter("funtranslationsQuota", func() any {
+			return funtranslationsQuota.Status()
+		}))
+	}
+	pokemonMux := pokemonmux.New(logger, pokeapiClient, funtranslationsClient, muxOpts...)
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", httpPort),
 		Handler: pokemonMux,
+		BaseContext: func(net.Listener) context.Context {
+			return baseCtx
+		},
 	}
 	go func() {
 		if err := server.ListenAndServe(); err != http.ErrServerClosed {
@@ -47,6 +170,9 @@ func main() {
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	// once the graceful period expires, stop waiting on the upstream APIs
+	stopAfterFunc := context.AfterFunc(ctx, cancelBaseCtx)
+	defer stopAfterFunc()
 
 	if err := server.Shutdown(ctx); err != nil {
 		logger.Fatalf("Server forced to shutdown: %s", err)
@@ -0,0 +1,151 @@
+package pokemonmux
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"malta895/pokedex/apiclients/funtranslations"
+	"malta895/pokedex/apiclients/pokeapi"
+	"malta895/pokedex/search"
+	"malta895/pokedex/textnorm"
+	"malta895/pokedex/translationrules"
+	"malta895/pokedex/types"
+	"net/http"
+	"sync"
+)
+
+const (
+	// DefaultBatchConcurrency is the default number of pokemon of a batch retrieved at the same time
+	DefaultBatchConcurrency = 4
+
+	// maxBatchSize bounds the pokemon of a batch request
+	maxBatchSize = 50
+
+	// maxBatchBodyBytes bounds the body of a batch request
+	maxBatchBodyBytes = 64 << 10
+)
+
+// batchRequest is the body of the `POST /pokemon/batch` requests
+type batchRequest struct {
+	// Pokemon are the identifiers of the pokemon, as in the path of `GET /pokemon/{pokemonName}`
+	Pokemon []string `json:"pokemon"`
+
+	// Translated selects the translated descriptions
+	Translated bool `json:"translated"`
+}
+
+// batchResponse is the body of the `POST /pokemon/batch` responses
+type batchResponse struct {
+	Results []batchResult `json:"results"`
+}
+
+// batchResult is the outcome of the lookup of a pokemon of the batch, either the pokemon or the problem
+type batchResult struct {
+	Identifier string         `json:"identifier"`
+	Pokemon    *types.Pokemon `json:"pokemon,omitempty"`
+	Error      *problem       `json:"error,omitempty"`
+}
+
+func buildBatchHandler(
+	logger *log.Logger,
+	pokeAPIClient pokeapi.Client,
+	funtranslationsClient funtranslations.Client,
+	normalizer *textnorm.Normalizer,
+	searchIndex *search.Index,
+	translationRules translationrules.Selector,
+	concurrency int,
+) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("received request %s: %s %s", requestIDFromContext(r.Context()), r.Method, r.URL.Path)
+		var batch batchRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)).Decode(&batch); err != nil {
+			logger.Printf("error decoding body of request %s: %v", requestIDFromContext(r.Context()), err)
+			handleRequestBodyError(logger, w, r,
+				`The request body must be a JSON object listing the pokemon, e.g. {"pokemon": ["pikachu"]}.`)
+			return
+		}
+		if len(batch.Pokemon) == 0 || len(batch.Pokemon) > maxBatchSize {
+			handleRequestBodyError(logger, w, r,
+				fmt.Sprintf("The request body must list between 1 and %d pokemon.", maxBatchSize))
+			return
+		}
+
+		var languages []string
+		// the translators only take english text, so the translated descriptions are never negotiated
+		if !batch.Translated {
+			languages = preferredLanguages(r)
+			w.Header().Set("Vary", "Accept-Language")
+		}
+
+		results := make([]batchResult, len(batch.Pokemon))
+		started := runConcurrently(r.Context(), len(batch.Pokemon), concurrency, func(ctx context.Context, i int) {
+			identifier := batch.Pokemon[i]
+			results[i] = batchResult{Identifier: identifier}
+
+			pokemonName := pokeapi.Slug(identifier)
+			if pokemonName == "" {
+				p := problemForError(pokeapi.ErrPokemonNotFound)
+				results[i].Error = &p
+				return
+			}
+			pokemon, err := retrievePokemon(ctx, pokeAPIClient, pokemonName, pokeapi.WithLanguages(languages...))
+			if err != nil {
+				logger.Printf("error retrieving pokemon %s for request %s: %v", pokemonName, requestIDFromContext(r.Context()), err)
+				p := lookupProblem(searchIndex, pokemonName, err)
+				results[i].Error = &p
+				return
+			}
+
+			if normalizer != nil {
+				pokemon.Description = normalizer.Normalize(pokemon.Description)
+			}
+			if batch.Translated {
+				translation := selectTranslator(pokemon, "", translationRules)
+				translatePokemonDescription(ctx, logger, pokemon, funtranslationsClient, translation)
+			}
+			results[i].Pokemon = pokemon
+		})
+		// the request was cancelled before the lookups of the remaining pokemon started
+		for i := started; i < len(batch.Pokemon); i++ {
+			p := problemForError(r.Context().Err())
+			results[i] = batchResult{Identifier: batch.Pokemon[i], Error: &p}
+		}
+
+		writeResponse(logger, w, r, http.StatusOK, batchResponse{Results: results})
+	}
+}
+
+// runConcurrently calls task for every index below n with ctx, running at most workers of them at a time,
+// and waits for them to complete. Once ctx is done no more tasks are started:
+// it returns the number of the started ones, the tasks of the following indexes never run.
+func runConcurrently(ctx context.Context, n int, workers int, task func(ctx context.Context, i int)) (started int) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	semaphore := make(chan struct{}, max(workers, 1))
+	for ; started < n; started++ {
+		if ctx.Err() != nil {
+			return started
+		}
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			return started
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			task(ctx, i)
+		}(started)
+	}
+	return started
+}
+
+func handleRequestBodyError(logger *log.Logger, w http.ResponseWriter, r *http.Request, detail string) {
+	logger.Printf("invalid body for request %s: %s", requestIDFromContext(r.Context()), detail)
+	writeProblem(logger, w, r, newProblem("invalid-request-body", http.StatusBadRequest,
+		"Invalid Request Body", detail))
+}
@@ -0,0 +1,73 @@
+package pokemonmux
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// languageQueryParam overrides the Accept-Language header, e.g. `?lang=it` or `?lang=it,de`
+const languageQueryParam = "lang"
+
+// preferredLanguages returns the languages requested by the caller, most preferred first:
+// the ones listed in the lang query parameter, or else the ones of the Accept-Language header
+func preferredLanguages(r *http.Request) []string {
+	if lang := r.URL.Query().Get(languageQueryParam); lang != "" {
+		var languages []string
+		for _, language := range strings.Split(lang, ",") {
+			if language = strings.TrimSpace(language); language != "" {
+				languages = append(languages, language)
+			}
+		}
+		return languages
+	}
+	return parseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// parseAcceptLanguage returns the languages of an Accept-Language header ordered by decreasing quality.
+// The wildcard and the languages with quality 0 are left out, since the fallback language is always applied.
+//
+// Reference: https://www.rfc-editor.org/rfc/rfc9110#name-accept-language
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type weightedLanguage struct {
+		language string
+		quality  float64
+	}
+	var weighted []weightedLanguage
+	for _, languageRange := range strings.Split(acceptLanguage, ",") {
+		language, params, _ := strings.Cut(languageRange, ";")
+		language = strings.TrimSpace(language)
+		if language == "" || language == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+		weighted = append(weighted, weightedLanguage{language, quality})
+	}
+
+	// stable, so that languages of equal quality keep the order of the header
+	slices.SortStableFunc(weighted, func(a, b weightedLanguage) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		}
+		return 0
+	})
+
+	languages := make([]string, 0, len(weighted))
+	for _, w := range weighted {
+		languages = append(languages, w.language)
+	}
+	return languages
+}
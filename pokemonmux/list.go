@@ -0,0 +1,358 @@
+package pokemonmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"malta895/pokedex/apiclients/pokeapi"
+	"malta895/pokedex/textnorm"
+	"malta895/pokedex/types"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+const (
+	// afterQueryParam and beforeQueryParam are the cursors of the listing,
+	// the National Pokédex numbers the page starts after or ends before
+	afterQueryParam  = "after"
+	beforeQueryParam = "before"
+
+	listLimitQueryParam = "limit"
+	legendaryQueryParam = "legendary"
+	mythicalQueryParam  = "mythical"
+
+	defaultListLimit = 20
+	maxListLimit     = 100
+
+	// listConcurrency bounds the species of a page retrieved at the same time
+	listConcurrency = 10
+
+	// maxScannedSpecies bounds the species a single request scans to fill its page,
+	// each one costing an upstream call when not cached.
+	// When it is reached the page is returned as is, with a Link to the species left to scan.
+	maxScannedSpecies = 2 * maxListLimit
+)
+
+// listFilters maps the query parameters filtering the listing to the PokeAPI categories of species
+var listFilters = []struct {
+	param  string
+	filter pokeapi.SpeciesFilter
+}{
+	{"habitat", pokeapi.FilterHabitat},
+	{"generation", pokeapi.FilterGeneration},
+	{"color", pokeapi.FilterColor},
+	{"shape", pokeapi.FilterShape},
+	{"type", pokeapi.FilterType},
+}
+
+// listResponse is the body of the `GET /pokemon` responses
+type listResponse struct {
+	Results []types.Pokemon `json:"results"`
+}
+
+// listQuery is the parsed query of a `GET /pokemon` request
+type listQuery struct {
+	limit  int
+	after  int
+	before int
+
+	// legendary and mythical are nil when the listing is not filtered by them
+	legendary *bool
+	mythical  *bool
+
+	// categories maps the PokeAPI categories to the slug of the requested one
+	categories map[pokeapi.SpeciesFilter]string
+}
+
+func buildListHandler(
+	logger *log.Logger,
+	pokeAPIClient pokeapi.Client,
+	normalizer *textnorm.Normalizer,
+) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("received request %s: %s %s", requestIDFromContext(r.Context()), r.Method, r.URL.Path)
+		query, err := parseListQuery(r)
+		if err != nil {
+			handleQueryParamError(logger, w, r, err)
+			return
+		}
+
+		candidates, err := listCandidates(r.Context(), pokeAPIClient, query)
+		var paramErr *queryParamError
+		if errors.As(err, &paramErr) {
+			handleQueryParamError(logger, w, r, err)
+			return
+		}
+		if err != nil {
+			handlePokemonError(logger, w, r, "error listing species", err)
+			return
+		}
+
+		// the page is filled scanning the candidates forwards from the after cursor,
+		// or backwards from the before one; start and end bound the scanned ones
+		var pokemon []*types.Pokemon
+		var start, end int
+		if query.before > 0 {
+			end = slices.IndexFunc(candidates, func(s pokeapi.SpeciesRef) bool { return s.ID >= query.before })
+			if end < 0 {
+				end = len(candidates)
+			}
+			backwards := slices.Clone(candidates[:end])
+			slices.Reverse(backwards)
+			var scanned int
+			pokemon, scanned, err = scanPage(r.Context(), pokeAPIClient, backwards, query)
+			slices.Reverse(pokemon)
+			start = end - scanned
+		} else {
+			start = slices.IndexFunc(candidates, func(s pokeapi.SpeciesRef) bool { return s.ID > query.after })
+			if start < 0 {
+				start = len(candidates)
+			}
+			var scanned int
+			pokemon, scanned, err = scanPage(r.Context(), pokeAPIClient, candidates[start:], query)
+			end = start + scanned
+		}
+		if err != nil {
+			handlePokemonError(logger, w, r, "error retrieving species", err)
+			return
+		}
+
+		if start < end {
+			if start > 0 {
+				w.Header().Add("Link", listLink(r, beforeQueryParam, candidates[start].ID, "prev"))
+			}
+			if end < len(candidates) {
+				w.Header().Add("Link", listLink(r, afterQueryParam, candidates[end-1].ID, "next"))
+			}
+		}
+
+		results := make([]types.Pokemon, 0, len(pokemon))
+		for _, p := range pokemon {
+			results = append(results, summarize(p, normalizer))
+		}
+		writeResponse(logger, w, r, http.StatusOK, listResponse{Results: results})
+	}
+}
+
+// parseListQuery parses the pagination and the filters of a `GET /pokemon` request
+func parseListQuery(r *http.Request) (listQuery, error) {
+	query := listQuery{categories: make(map[pokeapi.SpeciesFilter]string)}
+	var err error
+	if query.limit, err = intQueryParam(r, listLimitQueryParam, defaultListLimit, 1, maxListLimit); err != nil {
+		return listQuery{}, err
+	}
+	if query.after, err = cursorQueryParam(r, afterQueryParam); err != nil {
+		return listQuery{}, err
+	}
+	if query.before, err = cursorQueryParam(r, beforeQueryParam); err != nil {
+		return listQuery{}, err
+	}
+	if query.after > 0 && query.before > 0 {
+		return listQuery{}, &queryParamError{name: beforeQueryParam, expected: "omitted along with after"}
+	}
+	if query.legendary, err = optionalBoolQueryParam(r, legendaryQueryParam); err != nil {
+		return listQuery{}, err
+	}
+	if query.mythical, err = optionalBoolQueryParam(r, mythicalQueryParam); err != nil {
+		return listQuery{}, err
+	}
+
+	for _, f := range listFilters {
+		value := r.URL.Query().Get(f.param)
+		if value == "" {
+			continue
+		}
+		slug := pokeapi.Slug(value)
+		if slug == "" {
+			return listQuery{}, &queryParamError{name: f.param, expected: "an existing " + f.param}
+		}
+		query.categories[f.filter] = slug
+	}
+	return query, nil
+}
+
+// matches reports whether p satisfies the legendary and mythical filters of q
+func (q listQuery) matches(p *types.Pokemon) bool {
+	if q.legendary != nil && p.IsLegendary != *q.legendary {
+		return false
+	}
+	if q.mythical != nil && p.IsMythical != *q.mythical {
+		return false
+	}
+	return true
+}
+
+// listCandidates returns the species in every category requested by query, in National Pokédex order.
+// It returns a queryParamError for the categories that do not exist.
+func listCandidates(ctx context.Context, pokeAPIClient pokeapi.Client, query listQuery) ([]pokeapi.SpeciesRef, error) {
+	species, err := retrieveAllSpecies(ctx, pokeAPIClient)
+	if err != nil {
+		return nil, err
+	}
+	// the stored species are not shared with the client, e.g. its cache, since they are filtered in place
+	species = slices.Clone(species)
+
+	for _, f := range listFilters {
+		value, ok := query.categories[f.filter]
+		if !ok {
+			continue
+		}
+		inCategory, err := retrieveSpeciesBy(ctx, pokeAPIClient, f.filter, value)
+		if errors.Is(err, pokeapi.ErrFilterNotFound) {
+			return nil, &queryParamError{name: f.param, expected: "an existing " + f.param}
+		}
+		if err != nil {
+			return nil, err
+		}
+		// the IDs identify the species, the names listed by the types are the ones of their default variety
+		ids := make(map[int]struct{}, len(inCategory))
+		for _, s := range inCategory {
+			ids[s.ID] = struct{}{}
+		}
+		species = slices.DeleteFunc(species, func(s pokeapi.SpeciesRef) bool {
+			_, ok := ids[s.ID]
+			return !ok
+		})
+	}
+	return species, nil
+}
+
+// scanPage retrieves the species of the candidates in order until query.limit of them match its filters,
+// or maxScannedSpecies have been scanned. It returns the matching ones along with the number of candidates scanned
+func scanPage(
+	ctx context.Context,
+	pokeAPIClient pokeapi.Client,
+	candidates []pokeapi.SpeciesRef,
+	query listQuery,
+) ([]*types.Pokemon, int, error) {
+	var page []*types.Pokemon
+	var scanned int
+	candidates = candidates[:min(len(candidates), maxScannedSpecies)]
+	for scanned < len(candidates) && len(page) < query.limit {
+		chunk := candidates[scanned:min(scanned+query.limit, len(candidates))]
+		pokemon, err := retrieveSpeciesConcurrently(ctx, pokeAPIClient, chunk)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, p := range pokemon {
+			scanned++
+			if p != nil && query.matches(p) {
+				page = append(page, p)
+			}
+			if len(page) == query.limit {
+				break
+			}
+		}
+	}
+	return page, scanned, nil
+}
+
+// retrieveSpeciesConcurrently retrieves the species data of species, listConcurrency at a time, in the same order.
+// The species not found are nil, any other failure aborts the retrieval.
+func retrieveSpeciesConcurrently(
+	ctx context.Context,
+	pokeAPIClient pokeapi.Client,
+	species []pokeapi.SpeciesRef,
+) ([]*types.Pokemon, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pokemon := make([]*types.Pokemon, len(species))
+	var firstErr error
+	var once sync.Once
+	started := runConcurrently(ctx, len(species), listConcurrency, func(ctx context.Context, i int) {
+		p, err := retrieveSpecies(ctx, pokeAPIClient, species[i].Name)
+		if err != nil && !errors.Is(err, pokeapi.ErrPokemonNotFound) {
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+			return
+		}
+		pokemon[i] = p
+	})
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if started < len(species) {
+		// the species left are not missing, the caller gave up before retrieving them
+		return nil, ctx.Err()
+	}
+	return pokemon, nil
+}
+
+// summarize returns the fields of p describing its species, dropping the language and version of the description
+func summarize(p *types.Pokemon, normalizer *textnorm.Normalizer) types.Pokemon {
+	summary := types.Pokemon{
+		Name:        p.Name,
+		Description: p.Description,
+		Habitat:     p.Habitat,
+		IsLegendary: p.IsLegendary,
+		IsMythical:  p.IsMythical,
+		Generation:  p.Generation,
+	}
+	if normalizer != nil {
+		summary.Description = normalizer.Normalize(summary.Description)
+	}
+	return summary
+}
+
+// listLink returns the Link header value pointing at the page starting from cursor,
+// keeping the other query parameters of the request
+func listLink(r *http.Request, cursorParam string, cursor int, rel string) string {
+	query := r.URL.Query()
+	query.Del(afterQueryParam)
+	query.Del(beforeQueryParam)
+	query.Set(cursorParam, strconv.Itoa(cursor))
+	link := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+	return fmt.Sprintf("<%s>; rel=%q", link.String(), rel)
+}
+
+// cursorQueryParam returns the National Pokédex number of the cursor query parameter name, 0 when it is missing
+func cursorQueryParam(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 {
+		return 0, &queryParamError{name: name, expected: "a National Pokédex number"}
+	}
+	return parsed, nil
+}
+
+// optionalBoolQueryParam returns the value of the boolean query parameter name, nil when it is missing
+func optionalBoolQueryParam(r *http.Request, name string) (*bool, error) {
+	if r.URL.Query().Get(name) == "" {
+		return nil, nil
+	}
+	parsed, err := boolQueryParam(r, name)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func retrieveAllSpecies(ctx context.Context, pokeAPIClient pokeapi.Client) ([]pokeapi.SpeciesRef, error) {
+	ctx, cancel := context.WithTimeout(ctx, pokeAPICallTimeout)
+	defer cancel()
+
+	return pokeapi.AllSpecies(ctx, pokeAPIClient, pokeapi.DefaultListPageSize)
+}
+
+func retrieveSpeciesBy(
+	ctx context.Context,
+	pokeAPIClient pokeapi.Client,
+	filter pokeapi.SpeciesFilter,
+	value string,
+) ([]pokeapi.SpeciesRef, error) {
+	ctx, cancel := context.WithTimeout(ctx, pokeAPICallTimeout)
+	defer cancel()
+
+	return pokeAPIClient.ListSpeciesBy(ctx, filter, value)
+}
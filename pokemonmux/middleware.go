@@ -0,0 +1,85 @@
+package pokemonmux
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+
+	// maxRequestIDLength bounds the request IDs accepted from callers
+	maxRequestIDLength = 128
+)
+
+type requestIDContextKey struct{}
+
+// withRequestID assigns every request an ID, taken from the X-Request-ID header or generated,
+// echoing it in the response and making it available through requestIDFromContext
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		ctx := context.WithValue(r.Context(), requestIDContextKey{}, requestID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func requestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
+
+func newRequestID() string {
+	idBytes := make([]byte, 8)
+	rand.Read(idBytes)
+	return hex.EncodeToString(idBytes)
+}
+
+// withProblemFallback replaces the plain text 404 and 405 responses of serveMux with problems
+func withProblemFallback(logger *log.Logger, serveMux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler, pattern := serveMux.Handler(r)
+		if pattern != "" {
+			serveMux.ServeHTTP(w, r)
+			return
+		}
+
+		// let the ServeMux decide between 404 and 405, discarding its response
+		discarded := &statusRecorder{header: http.Header{}}
+		handler.ServeHTTP(discarded, r)
+		if allow := discarded.header.Get("Allow"); allow != "" {
+			w.Header().Set("Allow", allow)
+		}
+		if discarded.statusCode == http.StatusMethodNotAllowed {
+			writeProblem(logger, w, r, newProblem("method-not-allowed", http.StatusMethodNotAllowed,
+				"Method Not Allowed", "The resource does not support the request method."))
+			return
+		}
+		writeProblem(logger, w, r, resourceNotFoundProblem())
+	})
+}
+
+// statusRecorder is a http.ResponseWriter only keeping the headers and the status code
+type statusRecorder struct {
+	header     http.Header
+	statusCode int
+}
+
+func (s *statusRecorder) Header() http.Header {
+	return s.header
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (s *statusRecorder) WriteHeader(statusCode int) {
+	s.statusCode = statusCode
+}
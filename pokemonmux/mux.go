@@ -1,37 +1,115 @@
 package pokemonmux
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"malta895/pokedex/apiclients/funtranslations"
 	"malta895/pokedex/apiclients/pokeapi"
+	"malta895/pokedex/search"
+	"malta895/pokedex/textnorm"
+	"malta895/pokedex/translationrules"
 	"malta895/pokedex/types"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+	"unicode/utf8"
 )
 
-const pokemonNamePathWildcard = "pokemonName"
+const (
+	pokemonNamePathWildcard = "pokemonName"
+	subresourcePathWildcard = "subresource"
+
+	// rawQueryParam disables the normalization of the descriptions, e.g. `?raw=true`
+	rawQueryParam = "raw"
+
+	// versionQueryParam selects the game version, or version group, the description is taken from
+	versionQueryParam = "version"
+
+	// pokeAPICallTimeout bounds every call made to the PokeAPI client
+	pokeAPICallTimeout = 10 * time.Second
+
+	// funtranslationsCallTimeout bounds every call made to the funtranslations client
+	funtranslationsCallTimeout = 5 * time.Second
+)
 
 func New(
 	logger *log.Logger,
 	pokeAPIClient pokeapi.Client,
 	funtranslationsClient funtranslations.Client,
-) *http.ServeMux {
+	opts ...Option,
+) http.Handler {
+	o := newOptions(opts)
 	serveMux := http.NewServeMux()
 
 	// Endpoint 1: Basic Pokemon Information
 	serveMux.HandleFunc(
 		fmt.Sprintf("GET /pokemon/{%s}", pokemonNamePathWildcard),
-		buildPokemonHandler(logger, pokeAPIClient, funtranslationsClient, false),
+		buildPokemonHandler(
+			logger,
+			pokeAPIClient,
+			funtranslationsClient,
+			false,
+			o.normalizer,
+			o.searchIndex,
+			o.translationRules,
+			o.strictTranslations,
+		),
 	)
 
 	// Endpoint 2: Translated Pokemon Description
 	serveMux.HandleFunc(
 		fmt.Sprintf("GET /pokemon/translated/{%s}", pokemonNamePathWildcard),
-		buildPokemonHandler(logger, pokeAPIClient, funtranslationsClient, true),
+		buildPokemonHandler(
+			logger,
+			pokeAPIClient,
+			funtranslationsClient,
+			true,
+			o.normalizer,
+			o.searchIndex,
+			o.translationRules,
+			o.strictTranslations,
+		),
+	)
+
+	// Endpoint 3: Fuzzy search of the pokemon names
+	serveMux.HandleFunc("GET /pokemon/search", buildSearchHandler(logger, o.searchIndex))
+
+	// Endpoint 4: Pokemon subresources, e.g. the evolution chain.
+	// A single pattern serves all of them, since `/pokemon/{pokemonName}/evolution`
+	// would conflict with the translated endpoint pattern
+	serveMux.HandleFunc(
+		fmt.Sprintf("GET /pokemon/{%s}/{%s}", pokemonNamePathWildcard, subresourcePathWildcard),
+		buildSubresourceHandler(logger, map[string]http.HandlerFunc{
+			"evolution":    buildEvolutionHandler(logger, pokeAPIClient, o.searchIndex),
+			"descriptions": buildDescriptionsHandler(logger, pokeAPIClient, o.normalizer, o.searchIndex),
+		}),
 	)
 
-	return serveMux
+	// Endpoint 5: Paginated listing of the species
+	serveMux.HandleFunc("GET /pokemon", buildListHandler(logger, pokeAPIClient, o.normalizer))
+
+	// Endpoint 6: Lookup of many pokemon at once
+	serveMux.HandleFunc("POST /pokemon/batch", buildBatchHandler(
+		logger,
+		pokeAPIClient,
+		funtranslationsClient,
+		o.normalizer,
+		o.searchIndex,
+		o.translationRules,
+		o.batchConcurrency,
+	))
+
+	// Endpoint 7: Catalogue of the translators
+	serveMux.HandleFunc("GET /translators", buildTranslatorsHandler(logger))
+
+	// Service status, e.g. the circuit breakers state
+	serveMux.HandleFunc("GET /status", buildStatusHandler(logger, o.statusReporters))
+
+	return withRequestID(withProblemFallback(logger, serveMux))
 }
 
 func buildPokemonHandler(
@@ -39,65 +117,388 @@ func buildPokemonHandler(
 	pokeAPIClient pokeapi.Client,
 	funtranslationsClient funtranslations.Client,
 	translateDescription bool,
+	normalizer *textnorm.Normalizer,
+	searchIndex *search.Index,
+	translationRules translationrules.Selector,
+	strictTranslations bool,
 ) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		logger.Printf("received request: %s %s", r.Method, r.URL.Path)
-		pokemonName := r.PathValue(pokemonNamePathWildcard)
+		logger.Printf("received request %s: %s %s", requestIDFromContext(r.Context()), r.Method, r.URL.Path)
+		pokemonName, ok := pokemonSlug(logger, w, r)
+		if !ok {
+			return
+		}
+		raw, err := boolQueryParam(r, rawQueryParam)
+		if err != nil {
+			handleQueryParamError(logger, w, r, err)
+			return
+		}
+		var translatorType string
+		var withMetadata bool
+		strict := strictTranslations
+		if translateDescription {
+			if translatorType, err = translatorQueryParamValue(r); err != nil {
+				handleQueryParamError(logger, w, r, err)
+				return
+			}
+			if withMetadata, err = boolQueryParam(r, translationMetadataQueryParam); err != nil {
+				handleQueryParamError(logger, w, r, err)
+				return
+			}
+			requestedStrict, err := optionalBoolQueryParam(r, strictQueryParam)
+			if err != nil {
+				handleQueryParamError(logger, w, r, err)
+				return
+			}
+			if requestedStrict != nil {
+				strict = *requestedStrict
+			}
+		}
 
-		pokemon, err := pokeAPIClient.PokemonByName(pokemonName)
+		var lookupOpts []pokeapi.LookupOption
+		version, err := versionQueryParamValue(r)
 		if err != nil {
-			handlePokemonError(logger, w, "error retrieving pokemon", err)
+			handleQueryParamError(logger, w, r, err)
 			return
 		}
+		if version != "" {
+			lookupOpts = append(lookupOpts, pokeapi.WithVersion(version))
+		}
+		// the translators only take english text, so the translated description is never negotiated
+		if !translateDescription {
+			lookupOpts = append(lookupOpts, pokeapi.WithLanguages(preferredLanguages(r)...))
+			w.Header().Set("Vary", "Accept-Language")
+		}
+
+		pokemon, err := retrievePokemon(r.Context(), pokeAPIClient, pokemonName, lookupOpts...)
+		if err != nil {
+			handleLookupError(logger, w, r, searchIndex, pokemonName, "error retrieving pokemon", err)
+			return
+		}
+
+		if normalizer != nil && !raw {
+			pokemon.Description = normalizer.Normalize(pokemon.Description)
+		}
+		if translateDescription {
+			translation := selectTranslator(pokemon, translatorType, translationRules)
+			err := translatePokemonDescription(r.Context(), logger, pokemon, funtranslationsClient, translation)
+			if err != nil && strict {
+				writeProblem(logger, w, r, translationProblem(translation.FailureReason, translation.Translator))
+				return
+			}
+			if withMetadata {
+				pokemon.Translation = translation
+			}
+		}
 
+		if pokemon.Language != "" {
+			w.Header().Set("Content-Language", pokemon.Language)
+		}
+		// the species name is the canonical identifier, e.g. for lookups by national dex number
+		canonicalName := pokemonName
+		if pokemon.Name != "" {
+			canonicalName = pokemon.Name
+		}
 		if translateDescription {
-			translatePokemonDescription(logger, pokemon, funtranslationsClient)
+			setContentLocation(w, r, "/pokemon/translated", canonicalName)
+		} else {
+			setContentLocation(w, r, "/pokemon", canonicalName)
+		}
+		writeResponse(logger, w, r, http.StatusOK, pokemon)
+	}
+}
+
+// buildSubresourceHandler dispatches the requests to the handler of the requested subresource
+func buildSubresourceHandler(
+	logger *log.Logger,
+	handlers map[string]http.HandlerFunc,
+) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.PathValue(subresourcePathWildcard)]
+		if !ok {
+			writeProblem(logger, w, r, resourceNotFoundProblem())
+			return
+		}
+		handler(w, r)
+	}
+}
+
+func buildEvolutionHandler(
+	logger *log.Logger,
+	pokeAPIClient pokeapi.Client,
+	searchIndex *search.Index,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("received request %s: %s %s", requestIDFromContext(r.Context()), r.Method, r.URL.Path)
+		pokemonName, ok := pokemonSlug(logger, w, r)
+		if !ok {
+			return
+		}
+
+		pokemonName, err := resolveSpeciesName(r.Context(), pokeAPIClient, pokemonName)
+		if err != nil {
+			handleLookupError(logger, w, r, searchIndex, pokemonName, "error resolving species", err)
+			return
+		}
+		chain, err := retrieveEvolutionChain(r.Context(), pokeAPIClient, pokemonName)
+		if err != nil {
+			handleLookupError(logger, w, r, searchIndex, pokemonName, "error retrieving evolution chain", err)
+			return
+		}
+
+		setContentLocation(w, r, "/pokemon", pokemonName, "evolution")
+		writeResponse(logger, w, r, http.StatusOK, chain)
+	}
+}
+
+func buildDescriptionsHandler(
+	logger *log.Logger,
+	pokeAPIClient pokeapi.Client,
+	normalizer *textnorm.Normalizer,
+	searchIndex *search.Index,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("received request %s: %s %s", requestIDFromContext(r.Context()), r.Method, r.URL.Path)
+		pokemonName, ok := pokemonSlug(logger, w, r)
+		if !ok {
+			return
+		}
+		raw, err := boolQueryParam(r, rawQueryParam)
+		if err != nil {
+			handleQueryParamError(logger, w, r, err)
+			return
+		}
+
+		pokemonName, err = resolveSpeciesName(r.Context(), pokeAPIClient, pokemonName)
+		if err != nil {
+			handleLookupError(logger, w, r, searchIndex, pokemonName, "error resolving species", err)
+			return
+		}
+		descriptions, err := retrieveDescriptions(r.Context(), pokeAPIClient, pokemonName)
+		if err != nil {
+			handleLookupError(logger, w, r, searchIndex, pokemonName, "error retrieving descriptions", err)
+			return
+		}
+		if normalizer != nil && !raw {
+			descriptions = normalizeDescriptions(normalizer, descriptions)
+		}
+		setContentLocation(w, r, "/pokemon", pokemonName, "descriptions")
+
+		writeResponse(logger, w, r, http.StatusOK, descriptions)
+	}
+}
+
+func buildStatusHandler(
+	logger *log.Logger,
+	statusReporters map[string]func() any,
+) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := make(map[string]any, len(statusReporters))
+		for name, report := range statusReporters {
+			status[name] = report()
 		}
+		writeResponse(logger, w, r, http.StatusOK, status)
+	}
+}
+
+func retrievePokemon(
+	ctx context.Context,
+	pokeAPIClient pokeapi.Client,
+	pokemonName string,
+	opts ...pokeapi.LookupOption,
+) (*types.Pokemon, error) {
+	ctx, cancel := context.WithTimeout(ctx, pokeAPICallTimeout)
+	defer cancel()
+
+	return pokeAPIClient.PokemonByName(ctx, pokemonName, opts...)
+}
+
+func retrieveSpecies(ctx context.Context, pokeAPIClient pokeapi.Client, pokemonName string) (*types.Pokemon, error) {
+	ctx, cancel := context.WithTimeout(ctx, pokeAPICallTimeout)
+	defer cancel()
 
-		writeResponse(logger, w, http.StatusOK, pokemon)
+	return pokeAPIClient.Species(ctx, pokemonName)
+}
+
+// resolveSpeciesName returns the name of the species identified by slug, e.g. "pikachu" for "25",
+// so that the Content-Location of the subresources points at the species name as the one of `/pokemon` does.
+// Names are returned as they are, only National Pokédex numbers cost a lookup.
+func resolveSpeciesName(ctx context.Context, pokeAPIClient pokeapi.Client, slug string) (string, error) {
+	if _, err := strconv.Atoi(slug); err != nil {
+		return slug, nil
 	}
+	species, err := retrieveSpecies(ctx, pokeAPIClient, slug)
+	if err != nil {
+		return slug, err
+	}
+	return species.Name, nil
+}
+
+// normalizeDescriptions returns a copy of descriptions with every text normalized
+func normalizeDescriptions(normalizer *textnorm.Normalizer, descriptions types.Descriptions) types.Descriptions {
+	normalized := make(types.Descriptions, len(descriptions))
+	for version, byLanguage := range descriptions {
+		normalized[version] = make(map[string]string, len(byLanguage))
+		for language, text := range byLanguage {
+			normalized[version][language] = normalizer.Normalize(text)
+		}
+	}
+	return normalized
+}
+
+func retrieveDescriptions(
+	ctx context.Context,
+	pokeAPIClient pokeapi.Client,
+	pokemonName string,
+) (types.Descriptions, error) {
+	ctx, cancel := context.WithTimeout(ctx, pokeAPICallTimeout)
+	defer cancel()
+
+	return pokeAPIClient.Descriptions(ctx, pokemonName)
 }
 
+func retrieveEvolutionChain(
+	ctx context.Context,
+	pokeAPIClient pokeapi.Client,
+	pokemonName string,
+) (*types.EvolutionNode, error) {
+	ctx, cancel := context.WithTimeout(ctx, pokeAPICallTimeout)
+	defer cancel()
+
+	return pokeAPIClient.EvolutionChain(ctx, pokemonName)
+}
+
+// translatePokemonDescription translates the description of pokemon with the translator of translation,
+// recording the outcome in it; on failure the description is left untouched, and the error is returned
 func translatePokemonDescription(
+	ctx context.Context,
 	logger *log.Logger,
 	pokemon *types.Pokemon,
 	funtranslationsClient funtranslations.Client,
-) {
-	translatorType := funtranslations.TranslatorShakespeare
-	if pokemon.IsLegendary || pokemon.Habitat == "cave" {
-		translatorType = funtranslations.TranslatorYoda
+	translation *types.Translation,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, funtranslationsCallTimeout)
+	defer cancel()
+
+	translatedDesc, err := funtranslationsClient.FunTranslate(ctx, translation.Translator, pokemon.Description)
+	if err != nil {
+		logger.Printf("error translating description for pokemon %s with translator %s: %v", pokemon.Name, translation.Translator, err)
+		translation.FailureReason = translationFailureReason(err)
+		return err
+	}
+	pokemon.Description = translatedDesc
+	translation.Translated = true
+	return nil
+}
+
+// pokemonSlug returns the PokeAPI slug of the pokemon identifier of the request path,
+// e.g. "mr-mime" for "Mr. Mime"; if it has none, it responds with a not found problem
+func pokemonSlug(logger *log.Logger, w http.ResponseWriter, r *http.Request) (string, bool) {
+	identifier := r.PathValue(pokemonNamePathWildcard)
+	slug := pokeapi.Slug(identifier)
+	if slug == "" {
+		handlePokemonError(logger, w, r, "invalid pokemon identifier",
+			fmt.Errorf("%w: %q has no slug", pokeapi.ErrPokemonNotFound, identifier))
+		return "", false
 	}
-	translatedDesc, err := funtranslationsClient.FunTranslate(translatorType, pokemon.Description)
+	return slug, true
+}
+
+// setContentLocation points the Content-Location header at the canonical URL of the response,
+// made of the given path segments and of the query of the request
+func setContentLocation(w http.ResponseWriter, r *http.Request, base string, segments ...string) {
+	location, err := url.JoinPath(base, segments...)
 	if err != nil {
-		logger.Printf("error translating description for pokemon %s: %v", pokemon.Name, err)
 		return
 	}
-	pokemon.Description = translatedDesc
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
+	}
+	w.Header().Set("Content-Location", location)
 }
 
-func handlePokemonError(
+// boolQueryParam returns the value of the boolean query parameter name, false when it is missing
+func boolQueryParam(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, &queryParamError{name: name, expected: "a boolean"}
+	}
+	return parsed, nil
+}
+
+// versionQueryParamValue returns the game version or version group of the version query parameter,
+// empty when it is missing; it must be spelled as in the PokeAPI, e.g. "sword-shield"
+func versionQueryParamValue(r *http.Request) (string, error) {
+	version := r.URL.Query().Get(versionQueryParam)
+	if version != "" && pokeapi.Slug(version) != version {
+		return "", &queryParamError{name: versionQueryParam, expected: "a game version or version group, e.g. sword-shield"}
+	}
+	return version, nil
+}
+
+func handleQueryParamError(
 	logger *log.Logger,
 	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	logger.Printf("invalid query for request %s: %v", requestIDFromContext(r.Context()), err)
+	writeProblem(logger, w, r, newProblem("invalid-query-parameter", http.StatusBadRequest,
+		"Invalid Query Parameter", err.Error()))
+}
+
+// handleLookupError is handlePokemonError for the lookups of the pokemon named pokemonName,
+// suggesting the closest names when it does not exist
+func handleLookupError(
+	logger *log.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	searchIndex *search.Index,
+	pokemonName string,
 	message string,
 	err error,
 ) {
-	logger.Printf("%s: %v", message, err)
-	if err == pokeapi.ErrPokemonNotFound {
-		http.Error(w, "Not Found", http.StatusNotFound)
-		return
+	logger.Printf("%s for request %s: %v", message, requestIDFromContext(r.Context()), err)
+	writeProblem(logger, w, r, lookupProblem(searchIndex, pokemonName, err))
+}
+
+// lookupProblem is the problem reported for the failed lookup of the pokemon named pokemonName,
+// along with the closest names when it does not exist and is not too long to be searched
+func lookupProblem(searchIndex *search.Index, pokemonName string, err error) problem {
+	p := problemForError(err)
+	if errors.Is(err, pokeapi.ErrPokemonNotFound) && searchIndex != nil && utf8.RuneCountInString(pokemonName) <= maxSearchQueryLength {
+		for _, match := range searchIndex.Search(pokemonName, maxSuggestions) {
+			p.Suggestions = append(p.Suggestions, match.Name)
+		}
 	}
-	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	return p
+}
+
+func handlePokemonError(
+	logger *log.Logger,
+	w http.ResponseWriter,
+	r *http.Request,
+	message string,
+	err error,
+) {
+	logger.Printf("%s for request %s: %v", message, requestIDFromContext(r.Context()), err)
+	writeProblem(logger, w, r, problemForError(err))
 }
 
 func writeResponse(
 	logger *log.Logger,
 	w http.ResponseWriter,
+	r *http.Request,
 	statusCode int,
 	data interface{},
 ) {
 	respBody, err := json.Marshal(data)
 	if err != nil {
-		handlePokemonError(logger, w, "error marshalling response", err)
+		handlePokemonError(logger, w, r, "error marshalling response", err)
 		return
 	}
 
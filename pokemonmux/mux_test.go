@@ -1,31 +1,150 @@
 package pokemonmux
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/circuitbreaker"
 	"malta895/pokedex/apiclients/funtranslations"
 	"malta895/pokedex/apiclients/pokeapi"
+	"malta895/pokedex/search"
 	"malta895/pokedex/testutils"
+	"malta895/pokedex/textnorm"
+	"malta895/pokedex/translationrules"
 	"malta895/pokedex/types"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 type mockPokeAPIClient struct {
-	mockResp  *types.Pokemon
-	mockErr   error
-	foundName string
+	mockResp         *types.Pokemon
+	mockChain        *types.EvolutionNode
+	mockDescriptions types.Descriptions
+	mockErr          error
+	foundName        string
+	foundLanguages   []string
+	foundVersion     string
+
+	// mockSpecies is the list of every species, mockPokemon maps their names to their pokemon
+	// and mockCategories maps "filter/value" to the species of the filter categories
+	mockSpecies    []pokeapi.SpeciesRef
+	mockPokemon    map[string]*types.Pokemon
+	mockCategories map[string][]pokeapi.SpeciesRef
+
+	// pokemonLookups and speciesLookups count the calls to PokemonByName and Species
+	pokemonLookups int
+	speciesLookups int
+
+	// mu guards the found fields and the counters, the listing looks up species concurrently
+	mu sync.Mutex
 }
 
-func (mpc *mockPokeAPIClient) PokemonByName(name string) (*types.Pokemon, error) {
+func (mpc *mockPokeAPIClient) PokemonByName(ctx context.Context, name string, opts ...pokeapi.LookupOption) (*types.Pokemon, error) {
+	mpc.mu.Lock()
+	defer mpc.mu.Unlock()
 	mpc.foundName = name
+	lookupOpts := pokeapi.NewLookupOptions(opts...)
+	mpc.foundLanguages = lookupOpts.Languages
+	mpc.foundVersion = lookupOpts.Version
+	mpc.pokemonLookups++
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if mpc.mockPokemon != nil {
+		pokemon, ok := mpc.mockPokemon[name]
+		if !ok {
+			return nil, pokeapi.ErrPokemonNotFound
+		}
+		return pokemon, mpc.mockErr
+	}
 	return mpc.mockResp, mpc.mockErr
 }
 
+// Species returns the species fields of the pokemon PokemonByName would return
+func (mpc *mockPokeAPIClient) Species(ctx context.Context, name string) (*types.Pokemon, error) {
+	mpc.mu.Lock()
+	defer mpc.mu.Unlock()
+	mpc.foundName = name
+	mpc.speciesLookups++
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	pokemon := mpc.mockResp
+	if mpc.mockPokemon != nil {
+		var ok bool
+		if pokemon, ok = mpc.mockPokemon[name]; !ok {
+			return nil, pokeapi.ErrPokemonNotFound
+		}
+	}
+	if pokemon == nil {
+		return nil, mpc.mockErr
+	}
+	return &types.Pokemon{
+		Name:        pokemon.Name,
+		Description: pokemon.Description,
+		Language:    pokemon.Language,
+		Version:     pokemon.Version,
+		Habitat:     pokemon.Habitat,
+		IsLegendary: pokemon.IsLegendary,
+		IsMythical:  pokemon.IsMythical,
+		Generation:  pokemon.Generation,
+	}, mpc.mockErr
+}
+
+func (mpc *mockPokeAPIClient) Descriptions(ctx context.Context, name string) (types.Descriptions, error) {
+	mpc.foundName = name
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mpc.mockDescriptions, mpc.mockErr
+}
+
+func (mpc *mockPokeAPIClient) EvolutionChain(ctx context.Context, name string) (*types.EvolutionNode, error) {
+	mpc.foundName = name
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mpc.mockChain, mpc.mockErr
+}
+
+func (mpc *mockPokeAPIClient) ListSpecies(ctx context.Context, offset, limit int) (*pokeapi.SpeciesPage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	species := mpc.mockSpecies[min(offset, len(mpc.mockSpecies)):min(offset+limit, len(mpc.mockSpecies))]
+	return &pokeapi.SpeciesPage{Count: len(mpc.mockSpecies), Species: species}, mpc.mockErr
+}
+
+func (mpc *mockPokeAPIClient) ListSpeciesBy(ctx context.Context, filter pokeapi.SpeciesFilter, value string) ([]pokeapi.SpeciesRef, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	species, ok := mpc.mockCategories[string(filter)+"/"+value]
+	if !ok {
+		return nil, pokeapi.ErrFilterNotFound
+	}
+	return species, mpc.mockErr
+}
+
+func upstreamError(statusCode int, err error) error {
+	return &apiclients.UpstreamError{
+		Service:    "pokeapi",
+		URL:        "https://pokeapi.co/api/v2/pokemon-species/mewtwo",
+		StatusCode: statusCode,
+		Err:        err,
+	}
+}
+
 func TestBasicPokemonInfo(t *testing.T) {
 	testCases := map[string]struct {
 		mockPokeAPIClient *mockPokeAPIClient
@@ -54,6 +173,44 @@ func TestBasicPokemonInfo(t *testing.T) {
 			}`,
 			expectedStatusCode: http.StatusOK,
 		},
+		"should respond with the battle data if client gives it": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockResp: &types.Pokemon{
+					Name:        "pikachu",
+					Description: "some description",
+					Habitat:     "forest",
+					IsLegendary: false,
+					Types:       []string{"electric"},
+					Abilities: []types.Ability{
+						{Name: "static", IsHidden: false},
+						{Name: "lightning-rod", IsHidden: true},
+					},
+					BaseStats:      map[string]int{"hp": 35, "speed": 90},
+					Height:         4,
+					Weight:         60,
+					BaseExperience: 112,
+				},
+				mockErr: nil,
+			},
+			pokemonName: "pikachu",
+
+			expectedResp: `{
+				"name": "pikachu",
+				"description": "some description",
+				"habitat": "forest",
+				"isLegendary": false,
+				"types": ["electric"],
+				"abilities": [
+					{"name": "static", "isHidden": false},
+					{"name": "lightning-rod", "isHidden": true}
+				],
+				"baseStats": {"hp": 35, "speed": 90},
+				"height": 4,
+				"weight": 60,
+				"baseExperience": 112
+			}`,
+			expectedStatusCode: http.StatusOK,
+		},
 		"should respond with 404 Not Found if pokemon is not found": {
 			mockPokeAPIClient: &mockPokeAPIClient{
 				mockResp: nil,
@@ -61,7 +218,14 @@ func TestBasicPokemonInfo(t *testing.T) {
 			},
 			pokemonName: "mewtwo",
 
-			expectedResp:       "Not Found",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
 			expectedStatusCode: http.StatusNotFound,
 		},
 		"should respond with 500 Internal Server Error with unknown error": {
@@ -71,9 +235,121 @@ func TestBasicPokemonInfo(t *testing.T) {
 			},
 			pokemonName: "mewtwo",
 
-			expectedResp:       "Internal Server Error",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:internal-error",
+				"title": "Internal Server Error",
+				"status": 500,
+				"detail": "The request could not be completed.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
 			expectedStatusCode: http.StatusInternalServerError,
 		},
+		"should respond with 404 Not Found if the not found error is wrapped": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockResp: nil,
+				mockErr:  upstreamError(http.StatusNotFound, pokeapi.ErrPokemonNotFound),
+			},
+			pokemonName: "mewtwo",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		"should respond with 429 Too Many Requests if the api is rate limiting": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockResp: nil,
+				mockErr:  upstreamError(http.StatusTooManyRequests, pokeapi.ErrUnknown),
+			},
+			pokemonName: "mewtwo",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:upstream-rate-limited",
+				"title": "Upstream Rate Limited",
+				"status": 429,
+				"detail": "The pokeapi API is rate limiting the service.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusTooManyRequests,
+		},
+		"should respond with 503 Service Unavailable if the api is unavailable": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockResp: nil,
+				mockErr:  upstreamError(http.StatusServiceUnavailable, pokeapi.ErrUnknown),
+			},
+			pokemonName: "mewtwo",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:upstream-unavailable",
+				"title": "Upstream Unavailable",
+				"status": 503,
+				"detail": "The pokeapi API is unavailable.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		"should respond with 503 Service Unavailable if the circuit breaker is open": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockResp: nil,
+				mockErr:  fmt.Errorf("pokeapi: %w", circuitbreaker.ErrOpen),
+			},
+			pokemonName: "mewtwo",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:upstream-unavailable",
+				"title": "Upstream Unavailable",
+				"status": 503,
+				"detail": "An external API is failing, requests to it are suspended for a while.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+		"should respond with 504 Gateway Timeout if the api times out": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockResp: nil,
+				mockErr: &apiclients.UpstreamError{
+					Service: "pokeapi",
+					Err:     &url.Error{Op: "Get", Err: context.DeadlineExceeded},
+				},
+			},
+			pokemonName: "mewtwo",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:upstream-timeout",
+				"title": "Upstream Timeout",
+				"status": 504,
+				"detail": "An external API did not respond in time.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusGatewayTimeout,
+		},
+		"should respond with 502 Bad Gateway with any other upstream error": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockResp: nil,
+				mockErr:  upstreamError(http.StatusBadRequest, pokeapi.ErrUnknown),
+			},
+			pokemonName: "mewtwo",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:upstream-error",
+				"title": "Upstream Error",
+				"status": 502,
+				"detail": "The pokeapi API failed unexpectedly.",
+				"instance": "/pokemon/mewtwo",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadGateway,
+		},
 	}
 
 	for name, tt := range testCases {
@@ -87,6 +363,7 @@ func TestBasicPokemonInfo(t *testing.T) {
 			if err != nil {
 				t.Errorf("found err=%s; want nil", err)
 			}
+			req.Header.Set("X-Request-ID", "test-request-id")
 
 			respRecorder := httptest.NewRecorder()
 			handler.ServeHTTP(respRecorder, req)
@@ -101,9 +378,13 @@ func TestBasicPokemonInfo(t *testing.T) {
 
 			foundResp := respRecorder.Body.String()
 			if json.Valid([]byte(tt.expectedResp)) {
+				expectedContentType := "application/json"
+				if tt.expectedStatusCode != http.StatusOK {
+					expectedContentType = "application/problem+json"
+				}
 				contentType := respRecorder.Header().Get("Content-Type")
-				if contentType != "application/json" {
-					t.Errorf("found response contentType=%s; want application/json", contentType)
+				if contentType != expectedContentType {
+					t.Errorf("found response contentType=%s; want %s", contentType, expectedContentType)
 				}
 				bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
 				if err != nil {
@@ -127,11 +408,19 @@ type mockFunTranslationsClient struct {
 	mockErr             error
 	foundTranslatorType string
 	foundText           string
+
+	// mu guards the found fields, the batches translate concurrently
+	mu sync.Mutex
 }
 
-func (mft *mockFunTranslationsClient) FunTranslate(translatorType, text string) (string, error) {
+func (mft *mockFunTranslationsClient) FunTranslate(ctx context.Context, translatorType, text string) (string, error) {
+	mft.mu.Lock()
+	defer mft.mu.Unlock()
 	mft.foundTranslatorType = translatorType
 	mft.foundText = text
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	return mft.mockResp, mft.mockErr
 }
 
@@ -261,7 +550,14 @@ func TestTranslatedPokemonInfo(t *testing.T) {
 			},
 			pokemonName: "mewtwo",
 
-			expectedResp:       "Not Found",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/translated/mewtwo",
+				"requestId": "test-request-id"
+			}`,
 			expectedStatusCode: http.StatusNotFound,
 		},
 		"should respond with 500 Internal Server Error with unknown error": {
@@ -275,7 +571,14 @@ func TestTranslatedPokemonInfo(t *testing.T) {
 			},
 			pokemonName: "mewtwo",
 
-			expectedResp:       "Internal Server Error",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:internal-error",
+				"title": "Internal Server Error",
+				"status": 500,
+				"detail": "The request could not be completed.",
+				"instance": "/pokemon/translated/mewtwo",
+				"requestId": "test-request-id"
+			}`,
 			expectedStatusCode: http.StatusInternalServerError,
 		},
 	}
@@ -291,6 +594,7 @@ func TestTranslatedPokemonInfo(t *testing.T) {
 			if err != nil {
 				t.Errorf("found err=%s; want nil", err)
 			}
+			req.Header.Set("X-Request-ID", "test-request-id")
 
 			respRecorder := httptest.NewRecorder()
 			handler.ServeHTTP(respRecorder, req)
@@ -314,9 +618,13 @@ func TestTranslatedPokemonInfo(t *testing.T) {
 
 			foundResp := respRecorder.Body.String()
 			if json.Valid([]byte(tt.expectedResp)) {
+				expectedContentType := "application/json"
+				if tt.expectedStatusCode != http.StatusOK {
+					expectedContentType = "application/problem+json"
+				}
 				contentType := respRecorder.Header().Get("Content-Type")
-				if contentType != "application/json" {
-					t.Errorf("found response contentType=%s; want application/json", contentType)
+				if contentType != expectedContentType {
+					t.Errorf("found response contentType=%s; want %s", contentType, expectedContentType)
 				}
 				bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
 				if err != nil {
@@ -333,3 +641,1781 @@ func TestTranslatedPokemonInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestTranslationRules(t *testing.T) {
+	mythical := true
+	rules := &translationrules.RuleSet{
+		Rules: []translationrules.Rule{
+			{Name: "mythical", When: translationrules.Conditions{Mythical: &mythical}, Translator: "yoda"},
+			{Name: "sea", When: translationrules.Conditions{Habitats: []string{"sea"}}, Translator: "pirate"},
+		},
+		Default: "shakespeare",
+	}
+
+	testCases := map[string]struct {
+		pokemon *types.Pokemon
+
+		expectedTranslatorType string
+	}{
+		"should apply the first matching rule": {
+			pokemon:                &types.Pokemon{Name: "manaphy", Habitat: "sea", IsMythical: true},
+			expectedTranslatorType: "yoda",
+		},
+		"should apply a later rule": {
+			pokemon:                &types.Pokemon{Name: "tentacool", Habitat: "sea"},
+			expectedTranslatorType: "pirate",
+		},
+		"should apply the default translator": {
+			pokemon:                &types.Pokemon{Name: "zubat", Habitat: "cave"},
+			expectedTranslatorType: "shakespeare",
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tt.pokemon.Description = "some description"
+			funtranslationsClient := &mockFunTranslationsClient{mockResp: "some translation"}
+			handler := New(
+				log.Default(),
+				&mockPokeAPIClient{mockResp: tt.pokemon},
+				funtranslationsClient,
+				WithTranslationRules(rules),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/pokemon/translated/"+tt.pokemon.Name, nil)
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != http.StatusOK {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, http.StatusOK)
+			}
+			if found := funtranslationsClient.foundTranslatorType; found != tt.expectedTranslatorType {
+				t.Errorf("found translatorType=%s; want %s", found, tt.expectedTranslatorType)
+			}
+		})
+	}
+}
+
+func TestTranslators(t *testing.T) {
+	t.Run("should list the translators", func(t *testing.T) {
+		handler := New(log.Default(), &mockPokeAPIClient{}, &mockFunTranslationsClient{})
+
+		req := httptest.NewRequest(http.MethodGet, "/translators", nil)
+		respRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(respRecorder, req)
+
+		if respRecorder.Code != http.StatusOK {
+			t.Errorf("found statusCode=%d; want %d", respRecorder.Code, http.StatusOK)
+		}
+		var resp translatorsResponse
+		if err := json.Unmarshal(respRecorder.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("received error %v; want nil", err)
+		}
+		if !reflect.DeepEqual(resp.Translators, funtranslations.Translators()) {
+			t.Errorf("found translators=%+v; want %+v", resp.Translators, funtranslations.Translators())
+		}
+	})
+
+	testCases := map[string]struct {
+		query string
+
+		expectedTranslatorType string
+		expectedStatusCode     int
+	}{
+		"should translate with the requested translator": {
+			query: "?translator=pirate",
+
+			expectedTranslatorType: funtranslations.TranslatorPirate,
+			expectedStatusCode:     http.StatusOK,
+		},
+		"should ignore the case of the requested translator": {
+			query: "?translator=Pig-Latin",
+
+			expectedTranslatorType: funtranslations.TranslatorPigLatin,
+			expectedStatusCode:     http.StatusOK,
+		},
+		"should apply the translation rules by default": {
+			expectedTranslatorType: funtranslations.TranslatorShakespeare,
+			expectedStatusCode:     http.StatusOK,
+		},
+		"should reject an unknown translator": {
+			query: "?translator=elvish",
+
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			funtranslationsClient := &mockFunTranslationsClient{mockResp: "some translation"}
+			handler := New(
+				log.Default(),
+				&mockPokeAPIClient{mockResp: &types.Pokemon{Name: "pikachu", Habitat: "forest", Description: "some description"}},
+				funtranslationsClient,
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/pokemon/translated/pikachu"+tt.query, nil)
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != tt.expectedStatusCode {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			if found := funtranslationsClient.foundTranslatorType; found != tt.expectedTranslatorType {
+				t.Errorf("found translatorType=%s; want %s", found, tt.expectedTranslatorType)
+			}
+		})
+	}
+}
+
+func TestTranslationMetadata(t *testing.T) {
+	testCases := map[string]struct {
+		query   string
+		mockErr error
+
+		expectedResp string
+	}{
+		"should omit the translation by default": {
+			expectedResp: `{
+				"name": "zubat",
+				"description": "some translation",
+				"habitat": "cave",
+				"isLegendary": false
+			}`,
+		},
+		"should describe a translation selected by a rule": {
+			query: "?metadata=true",
+
+			expectedResp: `{
+				"name": "zubat",
+				"description": "some translation",
+				"habitat": "cave",
+				"isLegendary": false,
+				"translation": {
+					"translator": "yoda",
+					"selectedBy": "rule",
+					"rule": "cave",
+					"translated": true,
+					"originalDescription": "some description"
+				}
+			}`,
+		},
+		"should describe a requested translation": {
+			query: "?metadata=true&translator=pirate",
+
+			expectedResp: `{
+				"name": "zubat",
+				"description": "some translation",
+				"habitat": "cave",
+				"isLegendary": false,
+				"translation": {
+					"translator": "pirate",
+					"selectedBy": "request",
+					"translated": true,
+					"originalDescription": "some description"
+				}
+			}`,
+		},
+		"should describe a failed translation": {
+			query:   "?metadata=true",
+			mockErr: &apiclients.UpstreamError{Service: "funtranslations", StatusCode: http.StatusTooManyRequests},
+
+			expectedResp: `{
+				"name": "zubat",
+				"description": "some description",
+				"habitat": "cave",
+				"isLegendary": false,
+				"translation": {
+					"translator": "yoda",
+					"selectedBy": "rule",
+					"rule": "cave",
+					"translated": false,
+					"originalDescription": "some description",
+					"failureReason": "rate-limited"
+				}
+			}`,
+		},
+		"should reject an invalid metadata query parameter": {
+			query: "?metadata=maybe",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The metadata query parameter must be a boolean.",
+				"instance": "/pokemon/translated/zubat?metadata=maybe",
+				"requestId": "test-request-id"
+			}`,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := New(
+				log.Default(),
+				&mockPokeAPIClient{mockResp: &types.Pokemon{Name: "zubat", Habitat: "cave", Description: "some description"}},
+				&mockFunTranslationsClient{mockResp: "some translation", mockErr: tt.mockErr},
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/pokemon/translated/zubat"+tt.query, nil)
+			req.Header.Set("X-Request-ID", "test-request-id")
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			foundResp := respRecorder.Body.String()
+			bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+			if err != nil {
+				t.Error(err)
+			}
+			if !bodyOK {
+				t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+			}
+		})
+	}
+}
+
+func TestStrictTranslations(t *testing.T) {
+	testCases := map[string]struct {
+		serverStrict    bool
+		offlineFallback bool
+		query           string
+		mockErr         error
+
+		expectedStatusCode int
+		expectedType       string
+	}{
+		"should return the original description by default": {
+			mockErr: circuitbreaker.ErrOpen,
+
+			expectedStatusCode: http.StatusOK,
+		},
+		"should report an unsupported translator": {
+			query:   "?strict=true",
+			mockErr: funtranslations.ErrUnrecognizedTranslator,
+
+			expectedStatusCode: http.StatusBadGateway,
+			expectedType:       "urn:pokedex:problem:translation-unsupported-translator",
+		},
+		"should report an exhausted quota": {
+			query:   "?strict=true",
+			mockErr: funtranslations.ErrQuotaExhausted,
+
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedType:       "urn:pokedex:problem:translation-rate-limited",
+		},
+		"should report a rate limited translation": {
+			query:   "?strict=true",
+			mockErr: &apiclients.UpstreamError{StatusCode: http.StatusTooManyRequests, Err: funtranslations.ErrAPIStatusCode},
+
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedType:       "urn:pokedex:problem:translation-rate-limited",
+		},
+		"should report the remote failure if the offline fallback lacks the translator": {
+			offlineFallback: true,
+			query:           "?strict=true&translator=pirate",
+			mockErr:         &apiclients.UpstreamError{StatusCode: http.StatusTooManyRequests, Err: funtranslations.ErrAPIStatusCode},
+
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedType:       "urn:pokedex:problem:translation-rate-limited",
+		},
+		"should report an unavailable upstream": {
+			query:   "?strict=true",
+			mockErr: circuitbreaker.ErrOpen,
+
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedType:       "urn:pokedex:problem:translation-unavailable",
+		},
+		"should report a malformed response": {
+			query:   "?strict=true",
+			mockErr: funtranslations.ErrMalformedResponse,
+
+			expectedStatusCode: http.StatusBadGateway,
+			expectedType:       "urn:pokedex:problem:translation-malformed-response",
+		},
+		"should report any other failure": {
+			query:   "?strict=true",
+			mockErr: &apiclients.UpstreamError{StatusCode: http.StatusInternalServerError, Err: funtranslations.ErrAPIStatusCode},
+
+			expectedStatusCode: http.StatusBadGateway,
+			expectedType:       "urn:pokedex:problem:translation-failed",
+		},
+		"should apply the server default": {
+			serverStrict: true,
+			mockErr:      circuitbreaker.ErrOpen,
+
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedType:       "urn:pokedex:problem:translation-unavailable",
+		},
+		"should let the request override the server default": {
+			serverStrict: true,
+			query:        "?strict=false",
+			mockErr:      circuitbreaker.ErrOpen,
+
+			expectedStatusCode: http.StatusOK,
+		},
+		"should translate in strict mode": {
+			query: "?strict=true",
+
+			expectedStatusCode: http.StatusOK,
+		},
+		"should reject an invalid strict query parameter": {
+			query: "?strict=always",
+
+			expectedStatusCode: http.StatusBadRequest,
+			expectedType:       "urn:pokedex:problem:invalid-query-parameter",
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var funTranslationsClient funtranslations.Client = &mockFunTranslationsClient{mockResp: "some translation", mockErr: tt.mockErr}
+			if tt.offlineFallback {
+				funTranslationsClient = funtranslations.NewFallbackClient(log.Default(), funTranslationsClient, funtranslations.NewOfflineClient())
+			}
+			handler := New(
+				log.Default(),
+				&mockPokeAPIClient{mockResp: &types.Pokemon{Name: "zubat", Habitat: "cave", Description: "some description"}},
+				funTranslationsClient,
+				WithStrictTranslations(tt.serverStrict),
+			)
+
+			req := httptest.NewRequest(http.MethodGet, "/pokemon/translated/zubat"+tt.query, nil)
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != tt.expectedStatusCode {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			var body struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(respRecorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("received error %v; want nil", err)
+			}
+			if body.Type != tt.expectedType {
+				t.Errorf("found problem type=%s; want %s", body.Type, tt.expectedType)
+			}
+		})
+	}
+}
+
+func TestLanguageNegotiation(t *testing.T) {
+	testCases := map[string]struct {
+		path           string
+		acceptLanguage string
+		mockLanguage   string
+
+		expectedLanguages       []string
+		expectedContentLanguage string
+	}{
+		"should not request any language without preferences": {
+			path:         "/pokemon/pikachu",
+			mockLanguage: "en",
+
+			expectedLanguages:       nil,
+			expectedContentLanguage: "en",
+		},
+		"should order the accepted languages by quality": {
+			path:           "/pokemon/pikachu",
+			acceptLanguage: "de;q=0.5, it-IT, fr;q=0.8, *;q=0.1, ja;q=0",
+			mockLanguage:   "it",
+
+			expectedLanguages:       []string{"it-IT", "fr", "de"},
+			expectedContentLanguage: "it",
+		},
+		"should prefer the lang query parameter to the header": {
+			path:           "/pokemon/pikachu?lang=ja-Hrkt,%20fr",
+			acceptLanguage: "it",
+			mockLanguage:   "ja-Hrkt",
+
+			expectedLanguages:       []string{"ja-Hrkt", "fr"},
+			expectedContentLanguage: "ja-Hrkt",
+		},
+		"should not negotiate the language of the translated description": {
+			path:           "/pokemon/translated/pikachu?lang=it",
+			acceptLanguage: "de",
+			mockLanguage:   "en",
+
+			expectedLanguages:       nil,
+			expectedContentLanguage: "en",
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockPokeAPIClient := &mockPokeAPIClient{
+				mockResp: &types.Pokemon{Name: "pikachu", Description: "some description", Language: tt.mockLanguage},
+			}
+			handler := New(log.Default(), mockPokeAPIClient, &mockFunTranslationsClient{mockResp: "some translation"})
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != http.StatusOK {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, http.StatusOK)
+			}
+			if !reflect.DeepEqual(mockPokeAPIClient.foundLanguages, tt.expectedLanguages) {
+				t.Errorf("found languages=%v; want %v", mockPokeAPIClient.foundLanguages, tt.expectedLanguages)
+			}
+			if found := respRecorder.Header().Get("Content-Language"); found != tt.expectedContentLanguage {
+				t.Errorf("found Content-Language=%s; want %s", found, tt.expectedContentLanguage)
+			}
+		})
+	}
+}
+
+func TestVersionSelection(t *testing.T) {
+	testCases := map[string]struct {
+		path string
+
+		expectedStatusCode int
+		expectedVersion    string
+	}{
+		"should not request any version by default": {
+			path: "/pokemon/pikachu",
+
+			expectedVersion: "",
+		},
+		"should request the version of the query parameter": {
+			path: "/pokemon/pikachu?version=sword",
+
+			expectedVersion: "sword",
+		},
+		"should request the version of the translated description": {
+			path: "/pokemon/translated/pikachu?version=sword-shield",
+
+			expectedVersion: "sword-shield",
+		},
+		"should reject a version that is not spelled as in the pokeapi": {
+			path: "/pokemon/pikachu?version=../../pokemon/ditto",
+
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockPokeAPIClient := &mockPokeAPIClient{
+				mockResp: &types.Pokemon{Name: "pikachu", Description: "some description"},
+			}
+			handler := New(log.Default(), mockPokeAPIClient, &mockFunTranslationsClient{mockResp: "some translation"})
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			expectedStatusCode := http.StatusOK
+			if tt.expectedStatusCode != 0 {
+				expectedStatusCode = tt.expectedStatusCode
+			}
+			if respRecorder.Code != expectedStatusCode {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, expectedStatusCode)
+			}
+			if mockPokeAPIClient.foundVersion != tt.expectedVersion {
+				t.Errorf("found version=%s; want %s", mockPokeAPIClient.foundVersion, tt.expectedVersion)
+			}
+		})
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	testCases := map[string]struct {
+		mockPokeAPIClient *mockPokeAPIClient
+		pokemonName       string
+
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		"should respond with 200 OK and the descriptions by version and language": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockDescriptions: types.Descriptions{
+					"red":   {"en": "red text", "fr": "texte rouge"},
+					"sword": {"en": "sword text"},
+				},
+			},
+			pokemonName: "pikachu",
+
+			expectedResp: `{
+				"red": {"en": "red text", "fr": "texte rouge"},
+				"sword": {"en": "sword text"}
+			}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with 404 Not Found if pokemon is not found": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockErr: pokeapi.ErrPokemonNotFound,
+			},
+			pokemonName: "missingno",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/missingno/descriptions",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := New(log.Default(), tt.mockPokeAPIClient, nil)
+			req, err := http.NewRequest("GET", fmt.Sprintf("/pokemon/%s/descriptions", tt.pokemonName), nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if foundName := tt.mockPokeAPIClient.foundName; foundName != tt.pokemonName {
+				t.Errorf("found pokemonName=%s; want %s", foundName, tt.pokemonName)
+			}
+			if tt.expectedStatusCode != respRecorder.Code {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			foundResp := respRecorder.Body.String()
+			bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+			if err != nil {
+				t.Error(err)
+			}
+			if !bodyOK {
+				t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+			}
+		})
+	}
+}
+
+func TestDescriptionNormalization(t *testing.T) {
+	rawDescription := "When several of\nthese POKéMON\ngather, their\felectricity could\nbuild and cause\nlightning storms."
+	testCases := map[string]struct {
+		path string
+		opts []Option
+
+		expectedStatusCode      int
+		expectedDescription     string
+		expectedTranslationText string
+	}{
+		"should clean up the description by default": {
+			path: "/pokemon/pikachu",
+
+			expectedStatusCode:  http.StatusOK,
+			expectedDescription: "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+		},
+		"should apply the configured normalizer": {
+			path: "/pokemon/pikachu",
+			opts: []Option{WithNormalizer(textnorm.New(textnorm.WithCasingFixes()))},
+
+			expectedStatusCode:  http.StatusOK,
+			expectedDescription: "When several of these Pokémon gather, their electricity could build and cause lightning storms.",
+		},
+		"should leave the description untouched without a normalizer": {
+			path: "/pokemon/pikachu",
+			opts: []Option{WithNormalizer(nil)},
+
+			expectedStatusCode:  http.StatusOK,
+			expectedDescription: rawDescription,
+		},
+		"should leave the description untouched if raw is requested": {
+			path: "/pokemon/pikachu?raw=true",
+
+			expectedStatusCode:  http.StatusOK,
+			expectedDescription: rawDescription,
+		},
+		"should translate the normalized description": {
+			path: "/pokemon/translated/pikachu",
+
+			expectedStatusCode:      http.StatusOK,
+			expectedDescription:     "some translation",
+			expectedTranslationText: "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+		},
+		"should respond with 400 Bad Request if raw is not a boolean": {
+			path: "/pokemon/pikachu?raw=maybe",
+
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockPokeAPIClient := &mockPokeAPIClient{
+				mockResp: &types.Pokemon{Name: "pikachu", Description: rawDescription},
+			}
+			mockFunTranslationsClient := &mockFunTranslationsClient{mockResp: "some translation"}
+			handler := New(log.Default(), mockPokeAPIClient, mockFunTranslationsClient, tt.opts...)
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != tt.expectedStatusCode {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			if tt.expectedStatusCode != http.StatusOK {
+				return
+			}
+			var found types.Pokemon
+			if err := json.Unmarshal(respRecorder.Body.Bytes(), &found); err != nil {
+				t.Fatalf("found err=%s decoding the response; want nil", err)
+			}
+			if found.Description != tt.expectedDescription {
+				t.Errorf("found description=%q; want %q", found.Description, tt.expectedDescription)
+			}
+			if foundText := mockFunTranslationsClient.foundText; foundText != tt.expectedTranslationText {
+				t.Errorf("found text=%q; want %q", foundText, tt.expectedTranslationText)
+			}
+		})
+	}
+
+	t.Run("should normalize every description unless raw is requested", func(t *testing.T) {
+		mockPokeAPIClient := &mockPokeAPIClient{
+			mockDescriptions: types.Descriptions{"red": {"en": rawDescription}},
+		}
+		handler := New(log.Default(), mockPokeAPIClient, nil)
+
+		for path, expected := range map[string]string{
+			"/pokemon/pikachu/descriptions":          "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+			"/pokemon/pikachu/descriptions?raw=true": rawDescription,
+		} {
+			req, err := http.NewRequest("GET", path, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			var found types.Descriptions
+			if err := json.Unmarshal(respRecorder.Body.Bytes(), &found); err != nil {
+				t.Fatalf("found err=%s decoding the response; want nil", err)
+			}
+			if found["red"]["en"] != expected {
+				t.Errorf("found description=%q for %s; want %q", found["red"]["en"], path, expected)
+			}
+		}
+		if mockPokeAPIClient.mockDescriptions["red"]["en"] != rawDescription {
+			t.Errorf("found the descriptions of the client modified; want them untouched")
+		}
+	})
+}
+
+func TestAlternateIdentifiers(t *testing.T) {
+	testCases := map[string]struct {
+		path     string
+		mockName string
+
+		expectedName            string
+		expectedStatusCode      int
+		expectedContentLocation string
+	}{
+		"should look up a national dex number and point at the species name": {
+			path:     "/pokemon/025",
+			mockName: "pikachu",
+
+			expectedName:            "25",
+			expectedStatusCode:      http.StatusOK,
+			expectedContentLocation: "/pokemon/pikachu",
+		},
+		"should look up a name with spaces and punctuation": {
+			path:     "/pokemon/Mr.%20Mime",
+			mockName: "mr-mime",
+
+			expectedName:            "mr-mime",
+			expectedStatusCode:      http.StatusOK,
+			expectedContentLocation: "/pokemon/mr-mime",
+		},
+		"should look up a name with a gender sign": {
+			path:     "/pokemon/Nidoran%E2%99%80",
+			mockName: "nidoran-f",
+
+			expectedName:            "nidoran-f",
+			expectedStatusCode:      http.StatusOK,
+			expectedContentLocation: "/pokemon/nidoran-f",
+		},
+		"should keep the query in the canonical translated url": {
+			path:     "/pokemon/translated/PIKACHU?version=red",
+			mockName: "pikachu",
+
+			expectedName:            "pikachu",
+			expectedStatusCode:      http.StatusOK,
+			expectedContentLocation: "/pokemon/translated/pikachu?version=red",
+		},
+		"should look up the subresources of a normalized name": {
+			path: "/pokemon/Farfetch'd/evolution",
+
+			expectedName:            "farfetchd",
+			expectedStatusCode:      http.StatusOK,
+			expectedContentLocation: "/pokemon/farfetchd/evolution",
+		},
+		"should point the evolution of a national dex number at the species name": {
+			path:     "/pokemon/25/evolution",
+			mockName: "pikachu",
+
+			expectedName:            "pikachu",
+			expectedStatusCode:      http.StatusOK,
+			expectedContentLocation: "/pokemon/pikachu/evolution",
+		},
+		"should point the descriptions of a national dex number at the species name": {
+			path:     "/pokemon/25/descriptions?raw=true",
+			mockName: "pikachu",
+
+			expectedName:            "pikachu",
+			expectedStatusCode:      http.StatusOK,
+			expectedContentLocation: "/pokemon/pikachu/descriptions?raw=true",
+		},
+		"should respond with 404 Not Found without calling the client for names with no slug": {
+			path: "/pokemon/%E3%83%94%E3%82%AB%E3%83%81%E3%83%A5%E3%82%A6",
+
+			expectedName:       "",
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			mockPokeAPIClient := &mockPokeAPIClient{
+				mockResp:  &types.Pokemon{Name: tt.mockName, Description: "some description"},
+				mockChain: &types.EvolutionNode{Species: "farfetchd", EvolvesTo: []types.EvolutionNode{}},
+			}
+			handler := New(log.Default(), mockPokeAPIClient, &mockFunTranslationsClient{mockResp: "some translation"})
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != tt.expectedStatusCode {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			if mockPokeAPIClient.foundName != tt.expectedName {
+				t.Errorf("found pokemonName=%s; want %s", mockPokeAPIClient.foundName, tt.expectedName)
+			}
+			if found := respRecorder.Header().Get("Content-Location"); found != tt.expectedContentLocation {
+				t.Errorf("found Content-Location=%s; want %s", found, tt.expectedContentLocation)
+			}
+		})
+	}
+}
+
+func TestEvolutionChain(t *testing.T) {
+	testCases := map[string]struct {
+		mockPokeAPIClient *mockPokeAPIClient
+		pokemonName       string
+
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		"should respond with 200 OK and the branching evolution chain": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockChain: &types.EvolutionNode{
+					Species: "eevee",
+					EvolvesTo: []types.EvolutionNode{
+						{
+							Species:   "vaporeon",
+							Triggers:  []types.EvolutionTrigger{{Trigger: "use-item", Item: "water-stone"}},
+							EvolvesTo: []types.EvolutionNode{},
+						},
+						{
+							Species:   "espeon",
+							Triggers:  []types.EvolutionTrigger{{Trigger: "level-up", MinHappiness: 160, TimeOfDay: "day"}},
+							EvolvesTo: []types.EvolutionNode{},
+						},
+					},
+				},
+			},
+			pokemonName: "vaporeon",
+
+			expectedResp: `{
+				"species": "eevee",
+				"evolvesTo": [
+					{
+						"species": "vaporeon",
+						"triggers": [{"trigger": "use-item", "item": "water-stone"}],
+						"evolvesTo": []
+					},
+					{
+						"species": "espeon",
+						"triggers": [{"trigger": "level-up", "minHappiness": 160, "timeOfDay": "day"}],
+						"evolvesTo": []
+					}
+				]
+			}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with 404 Not Found if pokemon is not found": {
+			mockPokeAPIClient: &mockPokeAPIClient{
+				mockErr: pokeapi.ErrPokemonNotFound,
+			},
+			pokemonName: "missingno",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/missingno/evolution",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := New(log.Default(), tt.mockPokeAPIClient, nil)
+			req, err := http.NewRequest("GET", fmt.Sprintf("/pokemon/%s/evolution", tt.pokemonName), nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if foundName := tt.mockPokeAPIClient.foundName; foundName != tt.pokemonName {
+				t.Errorf("found pokemonName=%s; want %s", foundName, tt.pokemonName)
+			}
+			if tt.expectedStatusCode != respRecorder.Code {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			foundResp := respRecorder.Body.String()
+			bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+			if err != nil {
+				t.Error(err)
+			}
+			if !bodyOK {
+				t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+			}
+		})
+	}
+}
+
+// readyIndex returns a search index loaded with names
+func readyIndex(t *testing.T, names ...string) *search.Index {
+	t.Helper()
+	index := search.NewIndex(log.Default(), func(ctx context.Context) ([]string, error) {
+		return names, nil
+	})
+	if err := index.Refresh(context.Background()); err != nil {
+		t.Fatalf("found err=%s; want nil", err)
+	}
+	return index
+}
+
+func TestSearch(t *testing.T) {
+	testCases := map[string]struct {
+		searchIndex *search.Index
+		query       string
+
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		"should respond with 200 OK and the closest names first": {
+			searchIndex: readyIndex(t, "pikachu", "raichu", "bulbasaur"),
+			query:       "q=Pikachu",
+
+			expectedResp: `{
+				"query": "Pikachu",
+				"results": [{"name": "pikachu", "score": 1}, {"name": "raichu", "score": 0.43}]
+			}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with 200 OK and the misspelled names matches": {
+			searchIndex: readyIndex(t, "charmander", "charmeleon", "bulbasaur"),
+			query:       "q=charmandr&limit=1",
+
+			expectedResp: `{
+				"query": "charmandr",
+				"results": [{"name": "charmander", "score": 0.9}]
+			}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with 200 OK and no results if nothing matches": {
+			searchIndex: readyIndex(t, "pikachu"),
+			query:       "q=zzzzzz",
+
+			expectedResp:       `{"query": "zzzzzz", "results": []}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with 400 Bad Request if the query is missing": {
+			searchIndex: readyIndex(t, "pikachu"),
+			query:       "",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The q query parameter must be a non-empty string of at most 64 characters.",
+				"instance": "/pokemon/search",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"should respond with 400 Bad Request if the query is too long": {
+			searchIndex: readyIndex(t, "pikachu"),
+			query:       "q=" + strings.Repeat("é", 65),
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The q query parameter must be a non-empty string of at most 64 characters.",
+				"instance": "/pokemon/search?q=` + strings.Repeat("é", 65) + `",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"should respond with 400 Bad Request if the limit is out of range": {
+			searchIndex: readyIndex(t, "pikachu"),
+			query:       "q=pika&limit=51",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The limit query parameter must be an integer between 1 and 50.",
+				"instance": "/pokemon/search?q=pika&limit=51",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"should respond with 503 Service Unavailable if the index is not loaded yet": {
+			searchIndex: search.NewIndex(log.Default(), func(ctx context.Context) ([]string, error) {
+				return nil, errors.New("not loaded")
+			}),
+			query: "q=pika",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:search-unavailable",
+				"title": "Search Unavailable",
+				"status": 503,
+				"detail": "The index of the pokemon names is not available yet.",
+				"instance": "/pokemon/search?q=pika",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusServiceUnavailable,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := New(log.Default(), &mockPokeAPIClient{}, nil, WithSearchIndex(tt.searchIndex))
+			target := "/pokemon/search"
+			if tt.query != "" {
+				target += "?" + tt.query
+			}
+			req, err := http.NewRequest("GET", target, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if tt.expectedStatusCode != respRecorder.Code {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			foundResp := respRecorder.Body.String()
+			bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+			if err != nil {
+				t.Error(err)
+			}
+			if !bodyOK {
+				t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+			}
+		})
+	}
+}
+
+func TestNotFoundSuggestions(t *testing.T) {
+	testCases := map[string]struct {
+		path string
+
+		expectedResp string
+	}{
+		"should suggest the closest names for a pokemon": {
+			path: "/pokemon/pikachuu",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/pikachuu",
+				"requestId": "test-request-id",
+				"suggestions": ["pikachu"]
+			}`,
+		},
+		"should suggest the closest names for an evolution chain": {
+			path: "/pokemon/Bulbasur/evolution",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/Bulbasur/evolution",
+				"requestId": "test-request-id",
+				"suggestions": ["bulbasaur"]
+			}`,
+		},
+		"should not suggest anything if no name is close": {
+			path: "/pokemon/translated/missingno",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/translated/missingno",
+				"requestId": "test-request-id"
+			}`,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := New(
+				log.Default(),
+				&mockPokeAPIClient{mockErr: pokeapi.ErrPokemonNotFound},
+				nil,
+				WithSearchIndex(readyIndex(t, "pikachu", "raichu", "bulbasaur")),
+			)
+			req, err := http.NewRequest("GET", tt.path, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != http.StatusNotFound {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, http.StatusNotFound)
+			}
+			foundResp := respRecorder.Body.String()
+			bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+			if err != nil {
+				t.Error(err)
+			}
+			if !bodyOK {
+				t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+			}
+		})
+	}
+}
+
+// listingClient returns a mock client with a few species spread across the National Pokédex
+func listingClient() *mockPokeAPIClient {
+	pokemon := func(name, habitat string, legendary, mythical bool, pokemonTypes ...string) *types.Pokemon {
+		return &types.Pokemon{
+			Name:        name,
+			Description: "The\nmock " + name,
+			Language:    "en",
+			Habitat:     habitat,
+			IsLegendary: legendary,
+			IsMythical:  mythical,
+			Generation:  "generation-i",
+			Types:       pokemonTypes,
+			Height:      10,
+		}
+	}
+	return &mockPokeAPIClient{
+		mockSpecies: []pokeapi.SpeciesRef{
+			{ID: 1, Name: "bulbasaur"},
+			{ID: 4, Name: "charmander"},
+			{ID: 41, Name: "zubat"},
+			{ID: 144, Name: "articuno"},
+			{ID: 150, Name: "mewtwo"},
+			{ID: 151, Name: "mew"},
+		},
+		mockPokemon: map[string]*types.Pokemon{
+			"bulbasaur":  pokemon("bulbasaur", "grassland", false, false, "grass", "poison"),
+			"charmander": pokemon("charmander", "mountain", false, false, "fire"),
+			"zubat":      pokemon("zubat", "cave", false, false, "poison", "flying"),
+			"articuno":   pokemon("articuno", "rare", true, false, "ice", "flying"),
+			"mewtwo":     pokemon("mewtwo", "rare", true, false, "psychic"),
+			"mew":        pokemon("mew", "rare", false, true, "psychic"),
+		},
+		mockCategories: map[string][]pokeapi.SpeciesRef{
+			"pokemon-habitat/cave": {{ID: 41, Name: "zubat"}},
+			"type/fire":            {{ID: 4, Name: "charmander"}, {ID: 5, Name: "charmeleon"}},
+			"generation/generation-i": {
+				{ID: 1, Name: "bulbasaur"}, {ID: 4, Name: "charmander"}, {ID: 41, Name: "zubat"},
+				{ID: 144, Name: "articuno"}, {ID: 150, Name: "mewtwo"}, {ID: 151, Name: "mew"},
+			},
+		},
+	}
+}
+
+// listSummary is the summary of the pokemon of listingClient
+func listSummary(name, habitat string, extra string) string {
+	return fmt.Sprintf(`{"name": %q, "description": "The mock %s", "habitat": %q, "generation": "generation-i"%s}`,
+		name, name, habitat, extra)
+}
+
+func TestListPokemon(t *testing.T) {
+	var (
+		bulbasaur  = listSummary("bulbasaur", "grassland", `, "isLegendary": false`)
+		charmander = listSummary("charmander", "mountain", `, "isLegendary": false`)
+		zubat      = listSummary("zubat", "cave", `, "isLegendary": false`)
+		articuno   = listSummary("articuno", "rare", `, "isLegendary": true`)
+		mew        = listSummary("mew", "rare", `, "isLegendary": false, "isMythical": true`)
+	)
+	testCases := map[string]struct {
+		query string
+
+		expectedResp       string
+		expectedStatusCode int
+		expectedLinks      []string
+	}{
+		"should respond with the first page and a link to the next one": {
+			query: "limit=2",
+
+			expectedResp:       fmt.Sprintf(`{"results": [%s, %s]}`, bulbasaur, charmander),
+			expectedStatusCode: http.StatusOK,
+			expectedLinks:      []string{`</pokemon?after=4&limit=2>; rel="next"`},
+		},
+		"should respond with the page after the cursor and links to both sides": {
+			query: "after=4&limit=2",
+
+			expectedResp:       fmt.Sprintf(`{"results": [%s, %s]}`, zubat, articuno),
+			expectedStatusCode: http.StatusOK,
+			expectedLinks: []string{
+				`</pokemon?before=41&limit=2>; rel="prev"`,
+				`</pokemon?after=144&limit=2>; rel="next"`,
+			},
+		},
+		"should respond with the page before the cursor": {
+			query: "before=144&limit=2",
+
+			expectedResp:       fmt.Sprintf(`{"results": [%s, %s]}`, charmander, zubat),
+			expectedStatusCode: http.StatusOK,
+			expectedLinks: []string{
+				`</pokemon?before=4&limit=2>; rel="prev"`,
+				`</pokemon?after=41&limit=2>; rel="next"`,
+			},
+		},
+		"should skip the species not matching the legendary filter": {
+			query: "legendary=true&limit=1",
+
+			expectedResp:       fmt.Sprintf(`{"results": [%s]}`, articuno),
+			expectedStatusCode: http.StatusOK,
+			expectedLinks:      []string{`</pokemon?after=144&legendary=true&limit=1>; rel="next"`},
+		},
+		"should filter the mythical species": {
+			query: "mythical=true",
+
+			expectedResp:       fmt.Sprintf(`{"results": [%s]}`, mew),
+			expectedStatusCode: http.StatusOK,
+		},
+		"should filter by habitat": {
+			query: "habitat=Cave",
+
+			expectedResp:       fmt.Sprintf(`{"results": [%s]}`, zubat),
+			expectedStatusCode: http.StatusOK,
+		},
+		"should combine the category filters": {
+			query: "type=fire&generation=generation-i",
+
+			expectedResp:       fmt.Sprintf(`{"results": [%s]}`, charmander),
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with no results after the last species": {
+			query: "after=151",
+
+			expectedResp:       `{"results": []}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with 400 Bad Request if the category does not exist": {
+			query: "habitat=moon",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The habitat query parameter must be an existing habitat.",
+				"instance": "/pokemon?habitat=moon",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"should respond with 400 Bad Request if both cursors are set": {
+			query: "after=4&before=150",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The before query parameter must be omitted along with after.",
+				"instance": "/pokemon?after=4&before=150",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"should respond with 400 Bad Request if the cursor is invalid": {
+			query: "after=pikachu",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The after query parameter must be a National Pokédex number.",
+				"instance": "/pokemon?after=pikachu",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"should respond with 400 Bad Request if the limit is out of range": {
+			query: "limit=0",
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-query-parameter",
+				"title": "Invalid Query Parameter",
+				"status": 400,
+				"detail": "The limit query parameter must be an integer between 1 and 100.",
+				"instance": "/pokemon?limit=0",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			pokeAPIClient := listingClient()
+			handler := New(log.Default(), pokeAPIClient, nil)
+			req, err := http.NewRequest("GET", "/pokemon?"+tt.query, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if tt.expectedStatusCode != respRecorder.Code {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			if foundLinks := respRecorder.Header().Values("Link"); !reflect.DeepEqual(foundLinks, tt.expectedLinks) {
+				t.Errorf("found Link=%q; want %q", foundLinks, tt.expectedLinks)
+			}
+			foundResp := respRecorder.Body.String()
+			bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+			if err != nil {
+				t.Error(err)
+			}
+			if !bodyOK {
+				t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+			}
+			if pokeAPIClient.pokemonLookups != 0 {
+				t.Errorf("found %d pokemon lookups; want only species lookups", pokeAPIClient.pokemonLookups)
+			}
+		})
+	}
+
+	t.Run("should stop scanning at maxScannedSpecies and link to the rest", func(t *testing.T) {
+		pokeAPIClient := listingClient()
+		pokeAPIClient.mockSpecies = nil
+		for id := 1; id <= maxScannedSpecies+10; id++ {
+			name := fmt.Sprintf("pokemon-%d", id)
+			pokeAPIClient.mockSpecies = append(pokeAPIClient.mockSpecies, pokeapi.SpeciesRef{ID: id, Name: name})
+			pokeAPIClient.mockPokemon[name] = &types.Pokemon{Name: name}
+		}
+		handler := New(log.Default(), pokeAPIClient, nil)
+		req, err := http.NewRequest("GET", "/pokemon?legendary=true", nil)
+		if err != nil {
+			t.Errorf("found err=%s; want nil", err)
+		}
+
+		respRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(respRecorder, req)
+
+		if respRecorder.Code != http.StatusOK {
+			t.Errorf("found statusCode=%d; want %d", respRecorder.Code, http.StatusOK)
+		}
+		expectedLinks := []string{fmt.Sprintf(`</pokemon?after=%d&legendary=true>; rel="next"`, maxScannedSpecies)}
+		if foundLinks := respRecorder.Header().Values("Link"); !reflect.DeepEqual(foundLinks, expectedLinks) {
+			t.Errorf("found Link=%q; want %q", foundLinks, expectedLinks)
+		}
+		if pokeAPIClient.speciesLookups != maxScannedSpecies {
+			t.Errorf("found %d species lookups; want %d", pokeAPIClient.speciesLookups, maxScannedSpecies)
+		}
+	})
+}
+
+func TestBatch(t *testing.T) {
+	testCases := map[string]struct {
+		body string
+
+		expectedResp       string
+		expectedStatusCode int
+	}{
+		"should respond with 200 OK and every pokemon in order": {
+			body: `{"pokemon": ["Zubat", "CHARMANDER"]}`,
+
+			expectedResp: `{"results": [
+				{"identifier": "Zubat", "pokemon": {
+					"name": "zubat", "description": "The mock zubat", "language": "en", "habitat": "cave",
+					"isLegendary": false, "generation": "generation-i", "types": ["poison", "flying"], "height": 10
+				}},
+				{"identifier": "CHARMANDER", "pokemon": {
+					"name": "charmander", "description": "The mock charmander", "language": "en", "habitat": "mountain",
+					"isLegendary": false, "generation": "generation-i", "types": ["fire"], "height": 10
+				}}
+			]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should report the pokemon not found along with the ones found": {
+			body: `{"pokemon": ["mew", "mewthree", "???"]}`,
+
+			expectedResp: `{"results": [
+				{"identifier": "mew", "pokemon": {
+					"name": "mew", "description": "The mock mew", "language": "en", "habitat": "rare",
+					"isLegendary": false, "isMythical": true, "generation": "generation-i", "types": ["psychic"], "height": 10
+				}},
+				{"identifier": "mewthree", "error": {
+					"type": "urn:pokedex:problem:pokemon-not-found",
+					"title": "Pokemon Not Found",
+					"status": 404,
+					"detail": "The requested pokemon does not exist.",
+					"suggestions": ["mewtwo"]
+				}},
+				{"identifier": "???", "error": {
+					"type": "urn:pokedex:problem:pokemon-not-found",
+					"title": "Pokemon Not Found",
+					"status": 404,
+					"detail": "The requested pokemon does not exist."
+				}}
+			]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should translate the descriptions if requested": {
+			body: `{"pokemon": ["articuno"], "translated": true}`,
+
+			expectedResp: `{"results": [
+				{"identifier": "articuno", "pokemon": {
+					"name": "articuno", "description": "Translated, the mock articuno is", "language": "en", "habitat": "rare",
+					"isLegendary": true, "generation": "generation-i", "types": ["ice", "flying"], "height": 10
+				}}
+			]}`,
+			expectedStatusCode: http.StatusOK,
+		},
+		"should respond with 400 Bad Request if the body is not valid JSON": {
+			body: `["pikachu"]`,
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-request-body",
+				"title": "Invalid Request Body",
+				"status": 400,
+				"detail": "The request body must be a JSON object listing the pokemon, e.g. {\"pokemon\": [\"pikachu\"]}.",
+				"instance": "/pokemon/batch",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		"should respond with 400 Bad Request if the batch is empty": {
+			body: `{"pokemon": []}`,
+
+			expectedResp: `{
+				"type": "urn:pokedex:problem:invalid-request-body",
+				"title": "Invalid Request Body",
+				"status": 400,
+				"detail": "The request body must list between 1 and 50 pokemon.",
+				"instance": "/pokemon/batch",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := New(
+				log.Default(),
+				listingClient(),
+				&mockFunTranslationsClient{mockResp: "Translated, the mock articuno is"},
+				WithSearchIndex(readyIndex(t, "mew", "mewtwo", "zubat")),
+			)
+			req, err := http.NewRequest("POST", "/pokemon/batch", strings.NewReader(tt.body))
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			req.Header.Set("X-Request-ID", "test-request-id")
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if tt.expectedStatusCode != respRecorder.Code {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			foundResp := respRecorder.Body.String()
+			bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+			if err != nil {
+				t.Error(err)
+			}
+			if !bodyOK {
+				t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+			}
+		})
+	}
+}
+
+// concurrencyTrackingClient records the highest number of PokemonByName calls in flight at once
+type concurrencyTrackingClient struct {
+	*mockPokeAPIClient
+
+	inFlight    atomic.Int32
+	maxInFlight atomic.Int32
+}
+
+func (c *concurrencyTrackingClient) PokemonByName(ctx context.Context, name string, opts ...pokeapi.LookupOption) (*types.Pokemon, error) {
+	inFlight := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		maxInFlight := c.maxInFlight.Load()
+		if inFlight <= maxInFlight || c.maxInFlight.CompareAndSwap(maxInFlight, inFlight) {
+			break
+		}
+	}
+	time.Sleep(10 * time.Millisecond)
+	return c.mockPokeAPIClient.PokemonByName(ctx, name, opts...)
+}
+
+func TestBatchConcurrency(t *testing.T) {
+	testCases := map[string]struct {
+		opts []Option
+
+		expectedMaxInFlight int32
+	}{
+		"should retrieve up to the default number of pokemon at once": {
+			expectedMaxInFlight: DefaultBatchConcurrency,
+		},
+		"should retrieve up to the configured number of pokemon at once": {
+			opts: []Option{WithBatchConcurrency(2)},
+
+			expectedMaxInFlight: 2,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			client := &concurrencyTrackingClient{mockPokeAPIClient: listingClient()}
+			handler := New(log.Default(), client, nil, tt.opts...)
+			body := `{"pokemon": ["bulbasaur", "charmander", "zubat", "articuno", "mewtwo", "mew"]}`
+			req, err := http.NewRequest("POST", "/pokemon/batch", strings.NewReader(body))
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if respRecorder.Code != http.StatusOK {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, http.StatusOK)
+			}
+			if found := client.maxInFlight.Load(); found != tt.expectedMaxInFlight {
+				t.Errorf("found %d lookups in flight at once; want %d", found, tt.expectedMaxInFlight)
+			}
+		})
+	}
+}
+
+func TestRequestContextCancellation(t *testing.T) {
+	t.Run("should not reach the api clients once the request context is cancelled", func(t *testing.T) {
+		mockPokeAPIClient := &mockPokeAPIClient{
+			mockResp: &types.Pokemon{
+				Name:        "mewtwo",
+				Description: "some description",
+				Habitat:     "rare",
+				IsLegendary: true,
+			},
+		}
+		mockFunTranslationsClient := &mockFunTranslationsClient{
+			mockResp: "some description, this is",
+		}
+		handler := New(log.Default(), mockPokeAPIClient, mockFunTranslationsClient)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, err := http.NewRequestWithContext(ctx, "GET", "/pokemon/translated/mewtwo", nil)
+		if err != nil {
+			t.Errorf("found err=%s; want nil", err)
+		}
+
+		respRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(respRecorder, req)
+
+		if respRecorder.Code == http.StatusOK {
+			t.Errorf("found statusCode=%d; want an error status", respRecorder.Code)
+		}
+		if foundText := mockFunTranslationsClient.foundText; foundText != "" {
+			t.Errorf("found text=%s; want translation not requested", foundText)
+		}
+	})
+
+	t.Run("should not start the batch lookups once the request context is cancelled", func(t *testing.T) {
+		mockPokeAPIClient := &mockPokeAPIClient{mockResp: &types.Pokemon{Name: "mewtwo"}}
+		handler := New(log.Default(), mockPokeAPIClient, &mockFunTranslationsClient{})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, err := http.NewRequestWithContext(ctx, "POST", "/pokemon/batch", strings.NewReader(`{"pokemon": ["mewtwo", "mew"]}`))
+		if err != nil {
+			t.Errorf("found err=%s; want nil", err)
+		}
+		req.Header.Set("X-Request-ID", "test-request-id")
+
+		respRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(respRecorder, req)
+
+		if mockPokeAPIClient.pokemonLookups != 0 {
+			t.Errorf("found %d lookups; want 0", mockPokeAPIClient.pokemonLookups)
+		}
+		expectedResp := `{"results": [
+			{"identifier": "mewtwo", "error": {
+				"type": "urn:pokedex:problem:request-cancelled",
+				"title": "Request Cancelled",
+				"status": 503,
+				"detail": "The request was cancelled before it could be completed."
+			}},
+			{"identifier": "mew", "error": {
+				"type": "urn:pokedex:problem:request-cancelled",
+				"title": "Request Cancelled",
+				"status": 503,
+				"detail": "The request was cancelled before it could be completed."
+			}}
+		]}`
+		foundResp := respRecorder.Body.String()
+		bodyOK, err := testutils.JsonEq(foundResp, expectedResp)
+		if err != nil {
+			t.Error(err)
+		}
+		if !bodyOK {
+			t.Errorf("found respBody=%s; want %s", foundResp, expectedResp)
+		}
+	})
+}
+
+func TestStatus(t *testing.T) {
+	t.Run("should respond with every status section", func(t *testing.T) {
+		handler := New(
+			log.Default(),
+			&mockPokeAPIClient{},
+			&mockFunTranslationsClient{},
+			WithStatusReporter("circuitBreakers", func() any {
+				return []map[string]string{{"name": "pokeapi", "state": "open"}}
+			}),
+			WithStatusReporter("version", func() any {
+				return "test"
+			}),
+		)
+		req, err := http.NewRequest("GET", "/status", nil)
+		if err != nil {
+			t.Errorf("found err=%s; want nil", err)
+		}
+
+		respRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(respRecorder, req)
+
+		if respRecorder.Code != http.StatusOK {
+			t.Errorf("found statusCode=%d; want %d", respRecorder.Code, http.StatusOK)
+		}
+		expectedResp := `{
+			"circuitBreakers": [{"name": "pokeapi", "state": "open"}],
+			"version": "test"
+		}`
+		bodyOK, err := testutils.JsonEq(respRecorder.Body.String(), expectedResp)
+		if err != nil {
+			t.Error(err)
+		}
+		if !bodyOK {
+			t.Errorf("found respBody=%s; want %s", respRecorder.Body.String(), expectedResp)
+		}
+	})
+}
+
+func TestProblemResponses(t *testing.T) {
+	testCases := map[string]struct {
+		method string
+		path   string
+		accept string
+
+		expectedContentType string
+		expectedResp        string
+		expectedStatusCode  int
+	}{
+		"should respond with plain text if explicitly requested": {
+			method: "GET",
+			path:   "/pokemon/missingno",
+			accept: "text/plain",
+
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedResp:        "Pokemon Not Found: The requested pokemon does not exist.",
+			expectedStatusCode:  http.StatusNotFound,
+		},
+		"should respond with plain text to a text wildcard": {
+			method: "GET",
+			path:   "/pokemon/missingno",
+			accept: "text/*",
+
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedResp:        "Pokemon Not Found: The requested pokemon does not exist.",
+			expectedStatusCode:  http.StatusNotFound,
+		},
+		"should prefer problem json to a text wildcard weighted less than the full one": {
+			method: "GET",
+			path:   "/pokemon/missingno",
+			accept: "text/*;q=0.5, */*",
+
+			expectedContentType: "application/problem+json",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/missingno",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		"should prefer problem json if weighted more than plain text": {
+			method: "GET",
+			path:   "/pokemon/missingno",
+			accept: "text/plain;q=0.5, application/json",
+
+			expectedContentType: "application/problem+json",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:pokemon-not-found",
+				"title": "Pokemon Not Found",
+				"status": 404,
+				"detail": "The requested pokemon does not exist.",
+				"instance": "/pokemon/missingno",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		"should respond with a problem for unknown paths": {
+			method: "GET",
+			path:   "/pokedex",
+			accept: "*/*",
+
+			expectedContentType: "application/problem+json",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:resource-not-found",
+				"title": "Resource Not Found",
+				"status": 404,
+				"detail": "No resource is available at the requested path.",
+				"instance": "/pokedex",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		"should respond with a problem for unknown pokemon subresources": {
+			method: "GET",
+			path:   "/pokemon/pikachu/moves",
+			accept: "*/*",
+
+			expectedContentType: "application/problem+json",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:resource-not-found",
+				"title": "Resource Not Found",
+				"status": 404,
+				"detail": "No resource is available at the requested path.",
+				"instance": "/pokemon/pikachu/moves",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusNotFound,
+		},
+		"should respond with a problem for unsupported methods": {
+			method: "DELETE",
+			path:   "/pokemon/missingno",
+
+			expectedContentType: "application/problem+json",
+			expectedResp: `{
+				"type": "urn:pokedex:problem:method-not-allowed",
+				"title": "Method Not Allowed",
+				"status": 405,
+				"detail": "The resource does not support the request method.",
+				"instance": "/pokemon/missingno",
+				"requestId": "test-request-id"
+			}`,
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			handler := New(log.Default(), &mockPokeAPIClient{mockErr: pokeapi.ErrPokemonNotFound}, nil)
+			req, err := http.NewRequest(tt.method, tt.path, nil)
+			if err != nil {
+				t.Errorf("found err=%s; want nil", err)
+			}
+			req.Header.Set("X-Request-ID", "test-request-id")
+			req.Header.Set("Accept", tt.accept)
+
+			respRecorder := httptest.NewRecorder()
+			handler.ServeHTTP(respRecorder, req)
+
+			if tt.expectedStatusCode != respRecorder.Code {
+				t.Errorf("found statusCode=%d; want %d", respRecorder.Code, tt.expectedStatusCode)
+			}
+			if contentType := respRecorder.Header().Get("Content-Type"); contentType != tt.expectedContentType {
+				t.Errorf("found response contentType=%s; want %s", contentType, tt.expectedContentType)
+			}
+			foundResp := respRecorder.Body.String()
+			if json.Valid([]byte(tt.expectedResp)) {
+				bodyOK, err := testutils.JsonEq(foundResp, tt.expectedResp)
+				if err != nil {
+					t.Error(err)
+				}
+				if !bodyOK {
+					t.Errorf("found respBody=%s; want %s", foundResp, tt.expectedResp)
+				}
+			} else if strings.TrimSpace(foundResp) != tt.expectedResp {
+				t.Errorf("found respBody=%s, want %s", foundResp, tt.expectedResp)
+			}
+		})
+	}
+
+	t.Run("should generate a request id if none is provided", func(t *testing.T) {
+		handler := New(log.Default(), &mockPokeAPIClient{mockErr: pokeapi.ErrPokemonNotFound}, nil)
+		req, err := http.NewRequest("GET", "/pokemon/missingno", nil)
+		if err != nil {
+			t.Errorf("found err=%s; want nil", err)
+		}
+
+		respRecorder := httptest.NewRecorder()
+		handler.ServeHTTP(respRecorder, req)
+
+		requestID := respRecorder.Header().Get("X-Request-ID")
+		if requestID == "" {
+			t.Fatalf("found no X-Request-ID header; want a generated request id")
+		}
+		var found problem
+		if err := json.Unmarshal(respRecorder.Body.Bytes(), &found); err != nil {
+			t.Fatal(err)
+		}
+		if found.RequestID != requestID {
+			t.Errorf("found requestId=%s; want %s", found.RequestID, requestID)
+		}
+	})
+}
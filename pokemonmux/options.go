@@ -0,0 +1,84 @@
+package pokemonmux
+
+import (
+	"malta895/pokedex/search"
+	"malta895/pokedex/textnorm"
+	"malta895/pokedex/translationrules"
+)
+
+// Option customizes the ServeMux returned by New
+type Option func(*options)
+
+type options struct {
+	statusReporters    map[string]func() any
+	normalizer         *textnorm.Normalizer
+	searchIndex        *search.Index
+	batchConcurrency   int
+	translationRules   translationrules.Selector
+	strictTranslations bool
+}
+
+// WithStatusReporter adds a section named name to the `GET /status` endpoint,
+// its content is computed by report on every request
+func WithStatusReporter(name string, report func() any) Option {
+	return func(o *options) {
+		o.statusReporters[name] = report
+	}
+}
+
+// WithNormalizer sets the normalizer of the descriptions, by default they are only cleaned up
+// of control characters and extra whitespace; a nil normalizer leaves them untouched
+func WithNormalizer(normalizer *textnorm.Normalizer) Option {
+	return func(o *options) {
+		o.normalizer = normalizer
+	}
+}
+
+// WithSearchIndex sets the index of the pokemon names backing the `GET /pokemon/search` endpoint
+// and the suggestions of the not found responses; without it, searches are unavailable
+func WithSearchIndex(index *search.Index) Option {
+	return func(o *options) {
+		o.searchIndex = index
+	}
+}
+
+// WithBatchConcurrency sets the number of pokemon of a `POST /pokemon/batch` request retrieved at the same time,
+// DefaultBatchConcurrency by default; values lower than 1 are ignored
+func WithBatchConcurrency(workers int) Option {
+	return func(o *options) {
+		if workers >= 1 {
+			o.batchConcurrency = workers
+		}
+	}
+}
+
+// WithTranslationRules sets the rules selecting the translator of the translated descriptions,
+// by default translationrules.Default; a nil selector is ignored
+func WithTranslationRules(rules translationrules.Selector) Option {
+	return func(o *options) {
+		if rules != nil {
+			o.translationRules = rules
+		}
+	}
+}
+
+// WithStrictTranslations makes the translated endpoint respond with a problem when the translation fails,
+// instead of the original description; the strict query parameter overrides it for a request
+func WithStrictTranslations(strict bool) Option {
+	return func(o *options) {
+		o.strictTranslations = strict
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		statusReporters:  make(map[string]func() any),
+		normalizer:       textnorm.New(),
+		batchConcurrency: DefaultBatchConcurrency,
+		translationRules: translationrules.Default(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,155 @@
+package pokemonmux
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/circuitbreaker"
+	"malta895/pokedex/apiclients/pokeapi"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	problemContentType = "application/problem+json"
+
+	// problemTypePrefix namespaces the problem types of the service
+	problemTypePrefix = "urn:pokedex:problem:"
+)
+
+// problem is an RFC 7807 problem details object, the body of every error response
+//
+// Reference: https://www.rfc-editor.org/rfc/rfc7807
+type problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+
+	// Suggestions are the names closest to the one of a pokemon not found
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+func newProblem(problemType string, status int, title, detail string) problem {
+	return problem{
+		Type:   problemTypePrefix + problemType,
+		Title:  title,
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// queryParamError reports a query parameter with an invalid value, its message is shown to the caller
+type queryParamError struct {
+	name     string
+	expected string
+}
+
+func (e *queryParamError) Error() string {
+	return fmt.Sprintf("The %s query parameter must be %s.", e.name, e.expected)
+}
+
+// resourceNotFoundProblem is reported for the paths not matching any resource
+func resourceNotFoundProblem() problem {
+	return newProblem("resource-not-found", http.StatusNotFound,
+		"Resource Not Found", "No resource is available at the requested path.")
+}
+
+// problemForError maps the errors of the API clients to the problem reported to the caller:
+// cancelled requests, upstream rate limiting, unavailability and timeouts are reported as such,
+// any other upstream failure as a bad gateway.
+func problemForError(err error) problem {
+	if errors.Is(err, pokeapi.ErrPokemonNotFound) {
+		return newProblem("pokemon-not-found", http.StatusNotFound,
+			"Pokemon Not Found", "The requested pokemon does not exist.")
+	}
+	if errors.Is(err, context.Canceled) {
+		return newProblem("request-cancelled", http.StatusServiceUnavailable,
+			"Request Cancelled", "The request was cancelled before it could be completed.")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return newProblem("upstream-timeout", http.StatusGatewayTimeout,
+			"Upstream Timeout", "An external API did not respond in time.")
+	}
+	if errors.Is(err, circuitbreaker.ErrOpen) {
+		return newProblem("upstream-unavailable", http.StatusServiceUnavailable,
+			"Upstream Unavailable", "An external API is failing, requests to it are suspended for a while.")
+	}
+	var upstreamErr *apiclients.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		switch upstreamErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return newProblem("upstream-rate-limited", http.StatusTooManyRequests,
+				"Upstream Rate Limited", fmt.Sprintf("The %s API is rate limiting the service.", upstreamErr.Service))
+		case http.StatusServiceUnavailable:
+			return newProblem("upstream-unavailable", http.StatusServiceUnavailable,
+				"Upstream Unavailable", fmt.Sprintf("The %s API is unavailable.", upstreamErr.Service))
+		case http.StatusGatewayTimeout:
+			return newProblem("upstream-timeout", http.StatusGatewayTimeout,
+				"Upstream Timeout", fmt.Sprintf("The %s API did not respond in time.", upstreamErr.Service))
+		}
+		return newProblem("upstream-error", http.StatusBadGateway,
+			"Upstream Error", fmt.Sprintf("The %s API failed unexpectedly.", upstreamErr.Service))
+	}
+	return newProblem("internal-error", http.StatusInternalServerError,
+		"Internal Server Error", "The request could not be completed.")
+}
+
+// writeProblem writes p as the response, completed with the details of the request.
+// The problem is encoded as `application/problem+json`, unless the caller explicitly prefers plain text.
+func writeProblem(logger *log.Logger, w http.ResponseWriter, r *http.Request, p problem) {
+	p.Instance = r.URL.RequestURI()
+	p.RequestID = requestIDFromContext(r.Context())
+
+	if prefersPlainText(r.Header.Get("Accept")) {
+		text := fmt.Sprintf("%s: %s", p.Title, p.Detail)
+		if len(p.Suggestions) > 0 {
+			text += fmt.Sprintf(" Did you mean %s?", strings.Join(p.Suggestions, ", "))
+		}
+		http.Error(w, text, p.Status)
+		return
+	}
+
+	respBody, err := json.Marshal(p)
+	if err != nil {
+		logger.Printf("error marshalling problem: %v", err)
+		http.Error(w, http.StatusText(p.Status), p.Status)
+		return
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	w.Write(respBody)
+}
+
+// prefersPlainText reports whether the Accept header weights plain text more than JSON.
+// The `*/*` and `application/*` wildcards count in favor of JSON and `text/*` in favor of plain text,
+// so that plain text must be requested with a text media range.
+func prefersPlainText(accept string) bool {
+	var jsonQuality, textQuality float64
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case problemContentType, "application/json", "application/*", "*/*":
+			jsonQuality = max(jsonQuality, quality)
+		case "text/plain", "text/*":
+			textQuality = max(textQuality, quality)
+		}
+	}
+	return textQuality > jsonQuality
+}
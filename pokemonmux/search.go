@@ -0,0 +1,79 @@
+package pokemonmux
+
+import (
+	"log"
+	"malta895/pokedex/apiclients/pokeapi"
+	"malta895/pokedex/search"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	searchQueryParam      = "q"
+	searchLimitQueryParam = "limit"
+
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+	// maxSearchQueryLength bounds the runes of the searched names, since their matching cost grows with their length
+	maxSearchQueryLength = 64
+
+	// maxSuggestions bounds the names suggested by the not found responses
+	maxSuggestions = 3
+)
+
+// searchResponse is the body of the `GET /pokemon/search` responses
+type searchResponse struct {
+	Query   string         `json:"query"`
+	Results []search.Match `json:"results"`
+}
+
+func buildSearchHandler(
+	logger *log.Logger,
+	searchIndex *search.Index,
+) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("received request %s: %s %s", requestIDFromContext(r.Context()), r.Method, r.URL.Path)
+		query := r.URL.Query().Get(searchQueryParam)
+		if query == "" || utf8.RuneCountInString(query) > maxSearchQueryLength {
+			handleQueryParamError(logger, w, r, &queryParamError{
+				name:     searchQueryParam,
+				expected: "a non-empty string of at most " + strconv.Itoa(maxSearchQueryLength) + " characters",
+			})
+			return
+		}
+		limit, err := intQueryParam(r, searchLimitQueryParam, defaultSearchLimit, 1, maxSearchLimit)
+		if err != nil {
+			handleQueryParamError(logger, w, r, err)
+			return
+		}
+		if searchIndex == nil || !searchIndex.Ready() {
+			writeProblem(logger, w, r, newProblem("search-unavailable", http.StatusServiceUnavailable,
+				"Search Unavailable", "The index of the pokemon names is not available yet."))
+			return
+		}
+
+		results := searchIndex.Search(pokeapi.Slug(query), limit)
+		if results == nil {
+			results = []search.Match{}
+		}
+		writeResponse(logger, w, r, http.StatusOK, searchResponse{Query: query, Results: results})
+	}
+}
+
+// intQueryParam returns the value of the integer query parameter name, between minValue and maxValue,
+// defaultValue when it is missing
+func intQueryParam(r *http.Request, name string, defaultValue, minValue, maxValue int) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < minValue || parsed > maxValue {
+		return 0, &queryParamError{
+			name:     name,
+			expected: "an integer between " + strconv.Itoa(minValue) + " and " + strconv.Itoa(maxValue),
+		}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,131 @@
+package pokemonmux
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"malta895/pokedex/apiclients"
+	"malta895/pokedex/apiclients/circuitbreaker"
+	"malta895/pokedex/apiclients/funtranslations"
+	"malta895/pokedex/translationrules"
+	"malta895/pokedex/types"
+	"net/http"
+	"strings"
+)
+
+const (
+	// translatorQueryParam selects the translator of the translated description, e.g. `?translator=pirate`,
+	// overriding the translation rules
+	translatorQueryParam = "translator"
+
+	// translationMetadataQueryParam adds the translation object to the translated pokemon, e.g. `?metadata=true`
+	translationMetadataQueryParam = "metadata"
+
+	// strictQueryParam makes the failed translations an error, e.g. `?strict=true`, overriding WithStrictTranslations
+	strictQueryParam = "strict"
+)
+
+// The ways a translator is chosen, see types.Translation
+const (
+	selectedByRequest = "request"
+	selectedByRule    = "rule"
+	selectedByDefault = "default"
+)
+
+// The reasons of the failed translations, see types.Translation
+const (
+	failureUnsupportedTranslator = "unsupported-translator"
+	failureRateLimited           = "rate-limited"
+	failureUpstreamUnavailable   = "upstream-unavailable"
+	failureMalformedResponse     = "malformed-response"
+	failureUpstreamError         = "upstream-error"
+)
+
+// translatorsResponse is the body of the `GET /translators` responses
+type translatorsResponse struct {
+	Translators []funtranslations.Translator `json:"translators"`
+}
+
+func buildTranslatorsHandler(logger *log.Logger) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger.Printf("received request %s: %s %s", requestIDFromContext(r.Context()), r.Method, r.URL.Path)
+		writeResponse(logger, w, r, http.StatusOK, translatorsResponse{Translators: funtranslations.Translators()})
+	}
+}
+
+// translatorQueryParamValue returns the translator requested by the translator query parameter,
+// empty when it is missing
+func translatorQueryParamValue(r *http.Request) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(r.URL.Query().Get(translatorQueryParam)))
+	if value == "" {
+		return "", nil
+	}
+	if _, ok := funtranslations.LookupTranslator(value); !ok {
+		return "", &queryParamError{name: translatorQueryParam, expected: "one of the translators listed by GET /translators"}
+	}
+	return value, nil
+}
+
+// selectTranslator returns the translation of the description of pokemon with the requested translator,
+// or with the one selected by translationRules if none is requested
+func selectTranslator(pokemon *types.Pokemon, requested string, translationRules translationrules.Selector) *types.Translation {
+	translation := &types.Translation{OriginalDescription: pokemon.Description}
+	switch selection := translationRules.Select(pokemon); {
+	case requested != "":
+		translation.Translator, translation.SelectedBy = requested, selectedByRequest
+	case selection.Rule != "":
+		translation.Translator, translation.SelectedBy, translation.Rule = selection.Translator, selectedByRule, selection.Rule
+	default:
+		translation.Translator, translation.SelectedBy = selection.Translator, selectedByDefault
+	}
+	return translation
+}
+
+// translationFailureReason classifies the error of a failed translation
+func translationFailureReason(err error) string {
+	if errors.Is(err, funtranslations.ErrUnrecognizedTranslator) {
+		return failureUnsupportedTranslator
+	}
+	if errors.Is(err, funtranslations.ErrQuotaExhausted) {
+		return failureRateLimited
+	}
+	if errors.Is(err, circuitbreaker.ErrOpen) || errors.Is(err, context.DeadlineExceeded) {
+		return failureUpstreamUnavailable
+	}
+	if errors.Is(err, funtranslations.ErrMalformedResponse) {
+		return failureMalformedResponse
+	}
+	var upstreamErr *apiclients.UpstreamError
+	if errors.As(err, &upstreamErr) {
+		switch {
+		case upstreamErr.StatusCode == http.StatusTooManyRequests:
+			return failureRateLimited
+		case upstreamErr.StatusCode == 0 || upstreamErr.StatusCode == http.StatusServiceUnavailable:
+			return failureUpstreamUnavailable
+		}
+	}
+	return failureUpstreamError
+}
+
+// translationProblem returns the problem reporting a translation failed for reason, in strict mode:
+// the translators and responses the service cannot handle are reported as a bad gateway,
+// the rate limited and unavailable API as unavailable
+func translationProblem(reason, translator string) problem {
+	switch reason {
+	case failureUnsupportedTranslator:
+		return newProblem("translation-unsupported-translator", http.StatusBadGateway,
+			"Unsupported Translator", fmt.Sprintf("The %s translator is not supported by the translation backend.", translator))
+	case failureRateLimited:
+		return newProblem("translation-rate-limited", http.StatusServiceUnavailable,
+			"Translation Rate Limited", "The quota of translations is exhausted, try again later.")
+	case failureUpstreamUnavailable:
+		return newProblem("translation-unavailable", http.StatusServiceUnavailable,
+			"Translation Unavailable", "The translation API is unavailable, try again later.")
+	case failureMalformedResponse:
+		return newProblem("translation-malformed-response", http.StatusBadGateway,
+			"Malformed Translation", "The translation API responded with no translation.")
+	}
+	return newProblem("translation-failed", http.StatusBadGateway,
+		"Translation Failed", "The translation API failed unexpectedly.")
+}
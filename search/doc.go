@@ -0,0 +1,3 @@
+// Package search matches misspelled names against a list of known names,
+// e.g. to suggest "pikachu" to a caller asking for "pikachuu".
+package search
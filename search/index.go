@@ -0,0 +1,147 @@
+package search
+
+import (
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// notReadyRetryInterval bounds the wait before refreshing an Index that was never loaded
+const notReadyRetryInterval = time.Minute
+
+// Loader retrieves every name an Index matches against
+type Loader func(ctx context.Context) ([]string, error)
+
+// Match is a name matching a query, the higher the score the closer the name
+type Match struct {
+	Name  string  `json:"name"`
+	Score float64 `json:"score"`
+}
+
+// Status describes the content of an Index
+type Status struct {
+	Entries     int        `json:"entries"`
+	RefreshedAt *time.Time `json:"refreshedAt,omitempty"`
+}
+
+// Index matches queries against the names retrieved by its Loader, it is safe for concurrent use.
+// It is empty until the first successful Refresh.
+type Index struct {
+	logger *log.Logger
+	load   Loader
+
+	mu          sync.RWMutex
+	entries     []entry
+	refreshedAt time.Time
+}
+
+// entry is an indexed name, along with its trigrams
+type entry struct {
+	name     string
+	trigrams map[string]struct{}
+}
+
+// NewIndex returns an empty Index loading its names with load
+func NewIndex(logger *log.Logger, load Loader) *Index {
+	return &Index{logger: logger, load: load}
+}
+
+// Refresh replaces the names of the index with the ones retrieved by the Loader,
+// on failure the index keeps the previous names
+func (i *Index) Refresh(ctx context.Context) error {
+	names, err := i.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	entries := make([]entry, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(name)
+		entries = append(entries, entry{name: name, trigrams: trigrams(name)})
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.entries = entries
+	i.refreshedAt = time.Now()
+	return nil
+}
+
+// RefreshEvery refreshes the index straight away and then every interval, until ctx is done.
+// Failures are logged; until the first successful refresh, the index is retried at least every minute.
+func (i *Index) RefreshEvery(ctx context.Context, interval time.Duration) {
+	for {
+		if err := i.Refresh(ctx); err != nil && ctx.Err() == nil {
+			i.logger.Printf("error refreshing the search index: %v", err)
+		}
+
+		wait := interval
+		if !i.Ready() {
+			wait = min(interval, notReadyRetryInterval)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Ready reports whether the index has been loaded
+func (i *Index) Ready() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return !i.refreshedAt.IsZero()
+}
+
+// Search returns up to limit names matching query, the closest first
+func (i *Index) Search(query string, limit int) []Match {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" || limit <= 0 {
+		return nil
+	}
+	queryTrigrams := trigrams(query)
+
+	i.mu.RLock()
+	var matches []Match
+	for _, e := range i.entries {
+		if s := score(query, queryTrigrams, e); s >= MinScore {
+			matches = append(matches, Match{Name: e.name, Score: s})
+		}
+	}
+	i.mu.RUnlock()
+
+	slices.SortFunc(matches, func(a, b Match) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	for j := range matches {
+		matches[j].Score = roundScore(matches[j].Score)
+	}
+	return matches
+}
+
+// Status returns the number of names of the index and the time of its last refresh
+func (i *Index) Status() Status {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	status := Status{Entries: len(i.entries)}
+	if !i.refreshedAt.IsZero() {
+		refreshedAt := i.refreshedAt
+		status.RefreshedAt = &refreshedAt
+	}
+	return status
+}
@@ -0,0 +1,172 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var testNames = []string{
+	"bulbasaur", "ivysaur", "venusaur", "charmander", "charmeleon", "charizard",
+	"pichu", "pikachu", "raichu", "mr-mime", "mime-jr", "eevee", "pikipek",
+}
+
+func newLoadedIndex(t *testing.T) *Index {
+	t.Helper()
+	index := NewIndex(log.Default(), func(ctx context.Context) ([]string, error) {
+		return testNames, nil
+	})
+	if err := index.Refresh(context.Background()); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+	return index
+}
+
+func TestSearch(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		limit int
+
+		expectedNames []string
+	}{
+		"should find a name with an extra letter": {
+			query: "pikachuu",
+			limit: 1,
+
+			expectedNames: []string{"pikachu"},
+		},
+		"should find a name with a missing letter": {
+			query: "bulbsaur",
+			limit: 1,
+
+			expectedNames: []string{"bulbasaur"},
+		},
+		"should find a name with a wrong letter": {
+			query: "charmandar",
+			limit: 1,
+
+			expectedNames: []string{"charmander"},
+		},
+		"should rank the exact match first": {
+			query: "pichu",
+			limit: 2,
+
+			expectedNames: []string{"pichu", "pikachu"},
+		},
+		"should find the names starting with the query": {
+			query: "char",
+			limit: 3,
+
+			expectedNames: []string{"charizard", "charmander", "charmeleon"},
+		},
+		"should ignore the case of the query": {
+			query: "EEVEE",
+			limit: 1,
+
+			expectedNames: []string{"eevee"},
+		},
+		"should not match unrelated names": {
+			query: "xyzzy",
+			limit: 5,
+
+			expectedNames: nil,
+		},
+		"should not match an empty query": {
+			query: "  ",
+			limit: 5,
+
+			expectedNames: nil,
+		},
+	}
+
+	index := newLoadedIndex(t)
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var foundNames []string
+			for _, match := range index.Search(tt.query, tt.limit) {
+				foundNames = append(foundNames, match.Name)
+			}
+			if !reflect.DeepEqual(foundNames, tt.expectedNames) {
+				t.Errorf("Search(%s, %d) = %v; want %v", tt.query, tt.limit, foundNames, tt.expectedNames)
+			}
+		})
+	}
+
+	t.Run("should score only an exact match 1", func(t *testing.T) {
+		matches := index.Search("pikachu", 2)
+		if len(matches) != 2 || matches[0].Score != 1 || matches[1].Score >= 1 {
+			t.Errorf("Search(pikachu, 2) = %+v; want a score of 1 only for pikachu", matches)
+		}
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	t.Run("should be empty and not ready before the first refresh", func(t *testing.T) {
+		index := NewIndex(log.Default(), func(ctx context.Context) ([]string, error) {
+			return testNames, nil
+		})
+
+		if index.Ready() {
+			t.Errorf("found a ready index; want it not ready")
+		}
+		if found := index.Search("pikachu", 1); found != nil {
+			t.Errorf("Search(pikachu, 1) = %+v; want no matches", found)
+		}
+		if found := index.Status(); found != (Status{}) {
+			t.Errorf("found status %+v; want an empty one", found)
+		}
+	})
+
+	t.Run("should keep the previous names if a refresh fails", func(t *testing.T) {
+		fail := false
+		index := NewIndex(log.Default(), func(ctx context.Context) ([]string, error) {
+			if fail {
+				return nil, errors.New("pokeapi is down")
+			}
+			return testNames, nil
+		})
+		index.Refresh(context.Background())
+		fail = true
+
+		if err := index.Refresh(context.Background()); err == nil {
+			t.Errorf("received nil error; want the loader error")
+		}
+		if found := index.Status().Entries; found != len(testNames) {
+			t.Errorf("found %d entries; want %d", found, len(testNames))
+		}
+	})
+
+	t.Run("should refresh periodically until the context is done", func(t *testing.T) {
+		loads := make(chan struct{}, 10)
+		index := NewIndex(log.Default(), func(ctx context.Context) ([]string, error) {
+			loads <- struct{}{}
+			return testNames, nil
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		stopped := make(chan struct{})
+		go func() {
+			index.RefreshEvery(ctx, time.Millisecond)
+			close(stopped)
+		}()
+
+		for i := 0; i < 3; i++ {
+			select {
+			case <-loads:
+			case <-time.After(time.Second):
+				t.Fatalf("found %d refreshes; want 3", i)
+			}
+		}
+		cancel()
+		select {
+		case <-stopped:
+		case <-time.After(time.Second):
+			t.Fatalf("RefreshEvery did not return after the context was cancelled")
+		}
+		if !index.Ready() {
+			t.Errorf("found an index not ready; want it ready")
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// MinScore is the score a name must reach to match a query
+const MinScore = 0.4
+
+// score returns how close name is to query, from 0 to 1 for an exact match.
+// It is the best of the trigram similarity, catching swapped or missing syllables,
+// and of the edit similarity, catching typos; a name starting with query scores at least 0.8.
+func score(query string, queryTrigrams map[string]struct{}, e entry) float64 {
+	if query == e.name {
+		return 1
+	}
+	s := max(trigramSimilarity(queryTrigrams, e.trigrams), editSimilarity(query, e.name))
+	if strings.HasPrefix(e.name, query) {
+		s = max(s, 0.8+0.2*float64(utf8.RuneCountInString(query))/float64(utf8.RuneCountInString(e.name)))
+	}
+	// only an exact match scores 1
+	return min(s, 0.99)
+}
+
+// trigrams returns the set of the three-runes sequences of s, padded so that its edges count too
+func trigrams(s string) map[string]struct{} {
+	runes := []rune("  " + s + " ")
+	set := make(map[string]struct{}, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = struct{}{}
+	}
+	return set
+}
+
+// trigramSimilarity is the Jaccard index of two sets of trigrams
+func trigramSimilarity(a, b map[string]struct{}) float64 {
+	var shared int
+	for trigram := range a {
+		if _, ok := b[trigram]; ok {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// editSimilarity is 1 minus the Levenshtein distance of a and b relative to the longest of them
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// levenshtein returns the minimum number of insertions, deletions and substitutions turning a into b
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func roundScore(s float64) float64 {
+	return math.Round(s*100) / 100
+}
@@ -0,0 +1,27 @@
+package search
+
+import "testing"
+
+func TestLevenshtein(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+
+		expected int
+	}{
+		"should be 0 for equal strings":       {a: "pikachu", b: "pikachu", expected: 0},
+		"should count an insertion":           {a: "pikachu", b: "pikachuu", expected: 1},
+		"should count a deletion":             {a: "bulbasaur", b: "bulbsaur", expected: 1},
+		"should count a substitution":         {a: "charmander", b: "charmandar", expected: 1},
+		"should count every edit":             {a: "kitten", b: "sitting", expected: 3},
+		"should count the length of an empty": {a: "", b: "eevee", expected: 5},
+		"should count runes, not bytes":       {a: "flabébé", b: "flabebe", expected: 2},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if found := levenshtein([]rune(tt.a), []rune(tt.b)); found != tt.expected {
+				t.Errorf("levenshtein(%s, %s) = %d; want %d", tt.a, tt.b, found, tt.expected)
+			}
+		})
+	}
+}
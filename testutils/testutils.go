@@ -1,9 +1,11 @@
 package testutils
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sync"
 )
 
 func JsonEq(foundBody, expectedBody string) (bool, error) {
@@ -22,3 +24,20 @@ func JsonEq(foundBody, expectedBody string) (bool, error) {
 
 	return reflect.DeepEqual(o1, o2), nil
 }
+
+// WaitingContext returns a context derived from ctx that calls waiting the first time its Done method is called,
+// i.e. once its caller starts waiting on it; e.g. to know when the concurrent callers of a shared call have joined it
+func WaitingContext(ctx context.Context, waiting func()) context.Context {
+	return &waitingContext{Context: ctx, waiting: waiting}
+}
+
+type waitingContext struct {
+	context.Context
+	once    sync.Once
+	waiting func()
+}
+
+func (c *waitingContext) Done() <-chan struct{} {
+	c.once.Do(c.waiting)
+	return c.Context.Done()
+}
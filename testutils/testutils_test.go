@@ -1,6 +1,8 @@
 package testutils
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 )
@@ -68,3 +70,28 @@ func TestJsonEq(t *testing.T) {
 		})
 	}
 }
+
+func TestWaitingContext(t *testing.T) {
+	t.Run("should report the first wait on the context only", func(t *testing.T) {
+		var waits int
+		ctx := WaitingContext(context.Background(), func() { waits++ })
+		if waits != 0 {
+			t.Errorf("found %d waits before Done; want 0", waits)
+		}
+		ctx.Done()
+		ctx.Done()
+		if waits != 1 {
+			t.Errorf("found %d waits; want 1", waits)
+		}
+	})
+
+	t.Run("should be cancelled with its parent", func(t *testing.T) {
+		parent, cancel := context.WithCancel(context.Background())
+		ctx := WaitingContext(parent, func() {})
+		cancel()
+		<-ctx.Done()
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			t.Errorf("received error %v; want %v", ctx.Err(), context.Canceled)
+		}
+	})
+}
@@ -0,0 +1,3 @@
+// Package textnorm cleans up the flavor texts of the games, which were written for
+// fixed-width screens and carry their line breaks, page breaks and legacy spellings.
+package textnorm
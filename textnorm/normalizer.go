@@ -0,0 +1,75 @@
+package textnorm
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// softHyphenBreak is a word broken across lines at a soft hyphen (U+00AD), e.g. "emo\u00ad\ntions"
+	softHyphenBreak = regexp.MustCompile(`\x{00AD}[\s\x{00AD}]*`)
+
+	// hyphenBreak is a hyphenated word broken across lines, e.g. "self-\ndestruct"
+	hyphenBreak = regexp.MustCompile(`-[\n\f\r]+`)
+
+	// casingFixes replace the all-caps spellings of the early games
+	casingFixes = []struct {
+		pattern     *regexp.Regexp
+		replacement string
+	}{
+		{regexp.MustCompile(`\bPOK[ÉéE] ?BALLS\b`), "Poké Balls"},
+		{regexp.MustCompile(`\bPOK[ÉéE] ?BALL\b`), "Poké Ball"},
+		{regexp.MustCompile(`\bPOK[ÉéE]MON\b`), "Pokémon"},
+		{regexp.MustCompile(`\bTRAINERS\b`), "Trainers"},
+		{regexp.MustCompile(`\bTRAINER\b`), "Trainer"},
+	}
+)
+
+// Normalizer turns flavor texts into plain single-line text.
+// It always removes control characters, joins the words broken across lines at hyphens
+// and collapses whitespace, the other fixes are enabled by the options.
+type Normalizer struct {
+	fixCasing bool
+}
+
+// Option customizes a Normalizer
+type Option func(*Normalizer)
+
+// WithCasingFixes makes the Normalizer replace legacy all-caps spellings,
+// e.g. "POKéMON" becomes "Pokémon"
+func WithCasingFixes() Option {
+	return func(n *Normalizer) {
+		n.fixCasing = true
+	}
+}
+
+// New returns a Normalizer configured with opts
+func New(opts ...Option) *Normalizer {
+	n := &Normalizer{}
+	for _, opt := range opts {
+		opt(n)
+	}
+	return n
+}
+
+// Normalize returns text cleaned up by n
+func (n *Normalizer) Normalize(text string) string {
+	text = softHyphenBreak.ReplaceAllString(text, "")
+	text = hyphenBreak.ReplaceAllString(text, "-")
+	text = strings.Map(func(r rune) rune {
+		// line breaks and page breaks are control characters too, they separate words
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, text)
+	text = strings.Join(strings.Fields(text), " ")
+
+	if n.fixCasing {
+		for _, fix := range casingFixes {
+			text = fix.pattern.ReplaceAllString(text, fix.replacement)
+		}
+	}
+	return text
+}
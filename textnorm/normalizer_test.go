@@ -0,0 +1,69 @@
+package textnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]struct {
+		text string
+		opts []Option
+
+		expected string
+	}{
+		"should replace line and page breaks with spaces (mewtwo, red)": {
+			text: "It was created by\na scientist after\nyears of horrific\fgene splicing and\nDNA engineering\nexperiments.",
+
+			expected: "It was created by a scientist after years of horrific gene splicing and DNA engineering experiments.",
+		},
+		"should keep the legacy casing by default (pikachu, red)": {
+			text: "When several of\nthese POKéMON\ngather, their\felectricity could\nbuild and cause\nlightning storms.",
+
+			expected: "When several of these POKéMON gather, their electricity could build and cause lightning storms.",
+		},
+		"should fix the legacy casing if enabled (bulbasaur, red)": {
+			text: "A strange seed was\nplanted on its\nback at birth.\fThe plant sprouts\nand grows with\nthis POKéMON.",
+			opts: []Option{WithCasingFixes()},
+
+			expected: "A strange seed was planted on its back at birth. The plant sprouts and grows with this Pokémon.",
+		},
+		"should fix the casing of poke balls and trainers if enabled": {
+			text: "It hides in POKé BALLS and obeys its TRAINER's POKéBALL.",
+			opts: []Option{WithCasingFixes()},
+
+			expected: "It hides in Poké Balls and obeys its Trainer's Poké Ball.",
+		},
+		"should join a word broken at a soft hyphen": {
+			text: "The flame on its tail shows its emo\u00ad\ntions.",
+
+			expected: "The flame on its tail shows its emotions.",
+		},
+		"should drop a soft hyphen within a line": {
+			text: "It is very tem\u00adperamental.",
+
+			expected: "It is very temperamental.",
+		},
+		"should join a hyphenated word broken across lines": {
+			text: "It uses self-\ndestruct\nwhen cornered.",
+
+			expected: "It uses self-destruct when cornered.",
+		},
+		"should collapse whitespace and drop other control characters": {
+			text: "  Its body\r\n\tis\u0000 made of  rock.  ",
+
+			expected: "Its body is made of rock.",
+		},
+		"should leave clean text untouched": {
+			text: "Pikachu that can generate powerful electricity have cheek sacs that are extra soft and super stretchy.",
+			opts: []Option{WithCasingFixes()},
+
+			expected: "Pikachu that can generate powerful electricity have cheek sacs that are extra soft and super stretchy.",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if found := New(tt.opts...).Normalize(tt.text); found != tt.expected {
+				t.Errorf("Normalize(%q) = %q; want %q", tt.text, found, tt.expected)
+			}
+		})
+	}
+}
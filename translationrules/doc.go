@@ -0,0 +1,3 @@
+// Package translationrules selects the translator of the description of a pokemon,
+// according to an ordered list of rules loaded from a JSON file that can be changed while the service runs.
+package translationrules
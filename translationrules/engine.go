@@ -0,0 +1,133 @@
+package translationrules
+
+import (
+	"context"
+	"log"
+	"malta895/pokedex/types"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Engine applies the current RuleSet, that is replaced when its file changes; it is safe for concurrent use
+type Engine struct {
+	logger      *log.Logger
+	translators []string
+
+	rules atomic.Pointer[loadedRules]
+
+	// mu serializes the loads of the rules file
+	mu sync.Mutex
+}
+
+// loadedRules is a RuleSet along with its origin
+type loadedRules struct {
+	rules    *RuleSet
+	source   string
+	loadedAt time.Time
+
+	// modTime and size identify the version of the file the rules were loaded from
+	modTime time.Time
+	size    int64
+}
+
+// Status describes the rules applied by an Engine
+type Status struct {
+	// Source is the path of the rules file, or "default" for the Default rules
+	Source   string     `json:"source"`
+	Rules    int        `json:"rules"`
+	LoadedAt *time.Time `json:"loadedAt,omitempty"`
+}
+
+// NewEngine returns an Engine applying the Default rules, the rules loaded later
+// may only name the given translators, or any translator if none is given
+func NewEngine(logger *log.Logger, translators []string) *Engine {
+	e := &Engine{logger: logger, translators: translators}
+	e.rules.Store(&loadedRules{rules: Default(), source: "default"})
+	return e
+}
+
+// Select returns the translator of the description of pokemon according to the current rules
+func (e *Engine) Select(pokemon *types.Pokemon) Selection {
+	return e.rules.Load().rules.Select(pokemon)
+}
+
+// Status returns the origin of the current rules
+func (e *Engine) Status() Status {
+	current := e.rules.Load()
+	status := Status{Source: current.source, Rules: len(current.rules.Rules)}
+	if !current.loadedAt.IsZero() {
+		loadedAt := current.loadedAt
+		status.LoadedAt = &loadedAt
+	}
+	return status
+}
+
+// LoadFile replaces the current rules with the ones of the JSON file at path,
+// on failure the current rules are kept
+func (e *Engine) LoadFile(path string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rules, err := Parse(data, e.translators)
+	if err != nil {
+		return err
+	}
+	e.rules.Store(&loadedRules{
+		rules:    rules,
+		source:   path,
+		loadedAt: time.Now(),
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+	})
+	return nil
+}
+
+// WatchFile reloads the rules file at path every time it changes, checking it every interval until ctx is done.
+// Invalid versions of the file are logged and ignored, the previous rules stay in place.
+func (e *Engine) WatchFile(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// lastFailure identifies the last version of the file failing to load, so that it is reported once
+	var lastFailure struct {
+		modTime time.Time
+		size    int64
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			e.logger.Printf("error checking the translation rules file %s: %v", path, err)
+			continue
+		}
+		current := e.rules.Load()
+		if current.source == path && info.ModTime().Equal(current.modTime) && info.Size() == current.size {
+			continue
+		}
+		if info.ModTime().Equal(lastFailure.modTime) && info.Size() == lastFailure.size {
+			continue
+		}
+
+		if err := e.LoadFile(path); err != nil {
+			e.logger.Printf("error reloading the translation rules file %s, keeping the previous rules: %v", path, err)
+			lastFailure.modTime, lastFailure.size = info.ModTime(), info.Size()
+			continue
+		}
+		e.logger.Printf("reloaded the translation rules file %s", path)
+	}
+}
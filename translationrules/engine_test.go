@@ -0,0 +1,95 @@
+package translationrules
+
+import (
+	"context"
+	"errors"
+	"log"
+	"malta895/pokedex/types"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeRules(t *testing.T, path, data string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+	// the mod time is set explicitly, since consecutive writes may share it on coarse clocks
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+}
+
+func TestEngineLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	engine := NewEngine(log.Default(), testTranslators)
+	cave := types.Pokemon{Habitat: "cave"}
+
+	if found := engine.Select(&cave).Translator; found != "yoda" {
+		t.Errorf("found translator=%s; want yoda", found)
+	}
+	if found := engine.Status().Source; found != "default" {
+		t.Errorf("found source=%s; want default", found)
+	}
+
+	writeRules(t, path, `{"default": "pirate"}`, time.Now())
+	if err := engine.LoadFile(path); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+	if found := engine.Select(&cave).Translator; found != "pirate" {
+		t.Errorf("found translator=%s; want pirate", found)
+	}
+	if status := engine.Status(); status.Source != path || status.LoadedAt == nil {
+		t.Errorf("found status=%+v; want the source %s and the load time", status, path)
+	}
+
+	writeRules(t, path, `{"default": "minion"}`, time.Now())
+	if err := engine.LoadFile(path); !errors.Is(err, ErrInvalidRules) {
+		t.Fatalf("received error %v; want %v", err, ErrInvalidRules)
+	}
+	if found := engine.Select(&cave).Translator; found != "pirate" {
+		t.Errorf("found translator=%s after an invalid load; want pirate", found)
+	}
+}
+
+func TestEngineWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	modTime := time.Now().Add(-time.Hour)
+	writeRules(t, path, `{"default": "pirate"}`, modTime)
+
+	engine := NewEngine(log.Default(), testTranslators)
+	if err := engine.LoadFile(path); err != nil {
+		t.Fatalf("received error %v; want nil", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go engine.WatchFile(ctx, path, time.Millisecond)
+
+	waitForTranslator := func(expected string) {
+		t.Helper()
+		deadline := time.Now().Add(time.Second)
+		for {
+			found := engine.Select(&types.Pokemon{}).Translator
+			if found == expected {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("found translator=%s; want %s", found, expected)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	writeRules(t, path, `{"default": "yoda"}`, modTime.Add(time.Minute))
+	waitForTranslator("yoda")
+
+	// an invalid version keeps the previous rules in place, until a valid one replaces it
+	writeRules(t, path, `{"default": "minion"}`, modTime.Add(2*time.Minute))
+	time.Sleep(20 * time.Millisecond)
+	waitForTranslator("yoda")
+
+	writeRules(t, path, `{"default": "shakespeare"}`, modTime.Add(3*time.Minute))
+	waitForTranslator("shakespeare")
+}
@@ -0,0 +1,149 @@
+package translationrules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"malta895/pokedex/apiclients/funtranslations"
+	"malta895/pokedex/types"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidRules is returned for the rule sets that cannot be applied, e.g. naming an unknown translator
+var ErrInvalidRules = errors.New("invalid translation rules")
+
+// Selector selects the translator of the description of a pokemon
+type Selector interface {
+	Select(pokemon *types.Pokemon) Selection
+}
+
+// RuleSet selects the translator of the first matching rule, or else the default one
+type RuleSet struct {
+	Rules   []Rule `json:"rules"`
+	Default string `json:"default"`
+}
+
+// Rule maps the pokemon satisfying every condition of When to Translator
+type Rule struct {
+	// Name identifies the rule, e.g. in the logs
+	Name       string     `json:"name"`
+	When       Conditions `json:"when"`
+	Translator string     `json:"translator"`
+}
+
+// Conditions are the predicates over the fields of a pokemon, the unset ones are always satisfied.
+// The lists are satisfied by any of their values, e.g. a habitat of ["cave", "mountain"] matches both.
+type Conditions struct {
+	Habitats    []string `json:"habitat,omitempty"`
+	Legendary   *bool    `json:"legendary,omitempty"`
+	Mythical    *bool    `json:"mythical,omitempty"`
+	Types       []string `json:"type,omitempty"`
+	Generations []string `json:"generation,omitempty"`
+}
+
+// Selection is the translator selected for a pokemon, along with the name of the rule selecting it,
+// empty when it is the default one
+type Selection struct {
+	Translator string
+	Rule       string
+}
+
+// Default returns the historical rules of the service: legendary pokemon and the ones living in caves
+// are translated by Yoda, every other one by Shakespeare
+func Default() *RuleSet {
+	legendary := true
+	return &RuleSet{
+		Rules: []Rule{
+			{Name: "legendary", When: Conditions{Legendary: &legendary}, Translator: funtranslations.TranslatorYoda},
+			{Name: "cave", When: Conditions{Habitats: []string{"cave"}}, Translator: funtranslations.TranslatorYoda},
+		},
+		Default: funtranslations.TranslatorShakespeare,
+	}
+}
+
+// Parse decodes a JSON rule set and validates it against the known translators
+func Parse(data []byte, translators []string) (*RuleSet, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// unknown fields are rejected, so that a misspelled condition does not silently match everything
+	decoder.DisallowUnknownFields()
+	var rules RuleSet
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRules, err)
+	}
+	if err := rules.Validate(translators); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// Validate reports the problems of rs: missing names and translators, duplicated names,
+// and translators not among the known ones, if any is given
+func (rs *RuleSet) Validate(translators []string) error {
+	var problems []error
+	isKnown := func(translator string) bool {
+		return len(translators) == 0 || slices.Contains(translators, translator)
+	}
+
+	if rs.Default == "" {
+		problems = append(problems, errors.New("missing default translator"))
+	} else if !isKnown(rs.Default) {
+		problems = append(problems, fmt.Errorf("unknown default translator %q", rs.Default))
+	}
+	names := make(map[string]bool, len(rs.Rules))
+	for i, rule := range rs.Rules {
+		switch {
+		case rule.Name == "":
+			problems = append(problems, fmt.Errorf("rule %d: missing name", i))
+		case names[rule.Name]:
+			problems = append(problems, fmt.Errorf("rule %d: duplicated name %q", i, rule.Name))
+		}
+		names[rule.Name] = true
+
+		if rule.Translator == "" {
+			problems = append(problems, fmt.Errorf("rule %q: missing translator", rule.Name))
+		} else if !isKnown(rule.Translator) {
+			problems = append(problems, fmt.Errorf("rule %q: unknown translator %q", rule.Name, rule.Translator))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidRules, errors.Join(problems...))
+	}
+	return nil
+}
+
+// Select returns the translator of the first rule matching pokemon, or else the default one
+func (rs *RuleSet) Select(pokemon *types.Pokemon) Selection {
+	for _, rule := range rs.Rules {
+		if rule.When.Match(pokemon) {
+			return Selection{Translator: rule.Translator, Rule: rule.Name}
+		}
+	}
+	return Selection{Translator: rs.Default}
+}
+
+// Match reports whether pokemon satisfies every condition of c
+func (c Conditions) Match(pokemon *types.Pokemon) bool {
+	if c.Legendary != nil && pokemon.IsLegendary != *c.Legendary {
+		return false
+	}
+	if c.Mythical != nil && pokemon.IsMythical != *c.Mythical {
+		return false
+	}
+	if len(c.Habitats) > 0 && !containsFold(c.Habitats, pokemon.Habitat) {
+		return false
+	}
+	if len(c.Generations) > 0 && !containsFold(c.Generations, pokemon.Generation) {
+		return false
+	}
+	if len(c.Types) > 0 && !slices.ContainsFunc(pokemon.Types, func(t string) bool { return containsFold(c.Types, t) }) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	return slices.ContainsFunc(values, func(v string) bool { return strings.EqualFold(v, value) })
+}
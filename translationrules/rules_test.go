@@ -0,0 +1,165 @@
+package translationrules
+
+import (
+	"errors"
+	"malta895/pokedex/types"
+	"strings"
+	"testing"
+)
+
+var testTranslators = []string{"yoda", "shakespeare", "pirate"}
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		data string
+
+		expectedErr   error
+		expectedInErr string
+	}{
+		"should parse valid rules": {
+			data: `{
+				"rules": [
+					{"name": "sea", "when": {"habitat": ["sea"], "legendary": false}, "translator": "pirate"}
+				],
+				"default": "shakespeare"
+			}`,
+		},
+		"should parse a default only": {
+			data: `{"default": "yoda"}`,
+		},
+		"should reject malformed JSON": {
+			data:        `{"rules": [`,
+			expectedErr: ErrInvalidRules,
+		},
+		"should reject unknown conditions": {
+			data:          `{"rules": [{"name": "sea", "when": {"habitats": ["sea"]}, "translator": "pirate"}], "default": "yoda"}`,
+			expectedErr:   ErrInvalidRules,
+			expectedInErr: `unknown field "habitats"`,
+		},
+		"should reject a missing default": {
+			data:          `{"rules": []}`,
+			expectedErr:   ErrInvalidRules,
+			expectedInErr: "missing default translator",
+		},
+		"should reject unknown translators": {
+			data:          `{"rules": [{"name": "baby", "translator": "minion"}], "default": "yoda"}`,
+			expectedErr:   ErrInvalidRules,
+			expectedInErr: `rule "baby": unknown translator "minion"`,
+		},
+		"should reject duplicated names": {
+			data: `{
+				"rules": [
+					{"name": "sea", "translator": "pirate"},
+					{"name": "sea", "translator": "yoda"}
+				],
+				"default": "yoda"
+			}`,
+			expectedErr:   ErrInvalidRules,
+			expectedInErr: `duplicated name "sea"`,
+		},
+		"should reject unnamed rules": {
+			data:          `{"rules": [{"translator": "pirate"}], "default": "yoda"}`,
+			expectedErr:   ErrInvalidRules,
+			expectedInErr: "rule 0: missing name",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rules, err := Parse([]byte(tt.data), testTranslators)
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("received error %v; want %v", err, tt.expectedErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.expectedInErr) {
+				t.Errorf("found error=%q; want it to contain %q", err, tt.expectedInErr)
+			}
+			if err == nil && rules == nil {
+				t.Errorf("found rules=nil; want the parsed rules")
+			}
+		})
+	}
+}
+
+func TestSelect(t *testing.T) {
+	legendary, notLegendary := true, false
+	rules := &RuleSet{
+		Rules: []Rule{
+			{Name: "legendary", When: Conditions{Legendary: &legendary}, Translator: "yoda"},
+			{
+				Name:       "sea",
+				When:       Conditions{Habitats: []string{"sea", "waters-edge"}, Legendary: &notLegendary},
+				Translator: "pirate",
+			},
+			{Name: "old fire", When: Conditions{Types: []string{"fire"}, Generations: []string{"generation-i"}}, Translator: "yoda"},
+		},
+		Default: "shakespeare",
+	}
+
+	tests := map[string]struct {
+		pokemon types.Pokemon
+
+		expected Selection
+	}{
+		"should select the first matching rule": {
+			pokemon:  types.Pokemon{Habitat: "sea", IsLegendary: true},
+			expected: Selection{Translator: "yoda", Rule: "legendary"},
+		},
+		"should match any value of a list": {
+			pokemon:  types.Pokemon{Habitat: "waters-edge"},
+			expected: Selection{Translator: "pirate", Rule: "sea"},
+		},
+		"should ignore the case": {
+			pokemon:  types.Pokemon{Habitat: "Sea"},
+			expected: Selection{Translator: "pirate", Rule: "sea"},
+		},
+		"should match any type of the pokemon": {
+			pokemon:  types.Pokemon{Types: []string{"normal", "fire"}, Generation: "generation-i"},
+			expected: Selection{Translator: "yoda", Rule: "old fire"},
+		},
+		"should require every condition": {
+			pokemon:  types.Pokemon{Types: []string{"fire"}, Generation: "generation-ii"},
+			expected: Selection{Translator: "shakespeare"},
+		},
+		"should select the default translator": {
+			pokemon:  types.Pokemon{Habitat: "cave"},
+			expected: Selection{Translator: "shakespeare"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if found := rules.Select(&tt.pokemon); found != tt.expected {
+				t.Errorf("found selection=%+v; want %+v", found, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefault(t *testing.T) {
+	tests := map[string]struct {
+		pokemon types.Pokemon
+
+		expectedTranslator string
+	}{
+		"should translate legendary pokemon with yoda": {
+			pokemon:            types.Pokemon{IsLegendary: true, Habitat: "rare"},
+			expectedTranslator: "yoda",
+		},
+		"should translate cave pokemon with yoda": {
+			pokemon:            types.Pokemon{Habitat: "cave"},
+			expectedTranslator: "yoda",
+		},
+		"should translate the other pokemon with shakespeare": {
+			pokemon:            types.Pokemon{Habitat: "forest"},
+			expectedTranslator: "shakespeare",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if found := Default().Select(&tt.pokemon).Translator; found != tt.expectedTranslator {
+				t.Errorf("found translator=%s; want %s", found, tt.expectedTranslator)
+			}
+		})
+	}
+}
@@ -3,6 +3,74 @@ package types
 type Pokemon struct {
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// Language is the pokeapi name of the language of the description, e.g. "en" or "ja-Hrkt"
+	Language string `json:"language,omitempty"`
+	// Version is the pokeapi name of the game version the description is taken from, e.g. "red"
+	Version     string `json:"version,omitempty"`
 	Habitat     string `json:"habitat"`
 	IsLegendary bool   `json:"isLegendary"`
+	IsMythical  bool   `json:"isMythical,omitempty"`
+	// Generation is the pokeapi name of the generation that introduced the species, e.g. "generation-i"
+	Generation string `json:"generation,omitempty"`
+
+	// Types are the names of the pokemon types, ordered by slot
+	Types     []string  `json:"types,omitempty"`
+	Abilities []Ability `json:"abilities,omitempty"`
+	// BaseStats maps the stat names, e.g. "hp" or "special-attack", to their base value
+	BaseStats map[string]int `json:"baseStats,omitempty"`
+	// Height is expressed in decimetres
+	Height int `json:"height,omitempty"`
+	// Weight is expressed in hectograms
+	Weight         int `json:"weight,omitempty"`
+	BaseExperience int `json:"baseExperience,omitempty"`
+
+	// Translation describes the translation of the description, only when it is requested
+	Translation *Translation `json:"translation,omitempty"`
+}
+
+// Translation describes how the description of a pokemon has been translated
+type Translation struct {
+	Translator string `json:"translator"`
+	// SelectedBy tells how the translator has been chosen: "request", "rule" or "default"
+	SelectedBy string `json:"selectedBy"`
+	// Rule is the name of the translation rule selecting the translator, if any
+	Rule string `json:"rule,omitempty"`
+
+	// Translated is false when the translation failed, and the description is the original one
+	Translated          bool   `json:"translated"`
+	OriginalDescription string `json:"originalDescription"`
+	// FailureReason tells why the translation failed, e.g. "rate-limited"
+	FailureReason string `json:"failureReason,omitempty"`
+}
+
+// Descriptions maps the game versions to the descriptions of a pokemon in every language
+type Descriptions map[string]map[string]string
+
+type Ability struct {
+	Name     string `json:"name"`
+	IsHidden bool   `json:"isHidden"`
+}
+
+// EvolutionNode is a species of an evolution chain, along with the species it evolves into
+type EvolutionNode struct {
+	Species string `json:"species"`
+	// Triggers are the alternative ways of evolving into this species, empty for the first species of the chain
+	Triggers []EvolutionTrigger `json:"triggers,omitempty"`
+	// EvolvesTo has more than one species when the chain branches, e.g. for eevee
+	EvolvesTo []EvolutionNode `json:"evolvesTo"`
+}
+
+// EvolutionTrigger describes the conditions of an evolution, the unset ones are omitted
+type EvolutionTrigger struct {
+	// Trigger is the kind of event causing the evolution, e.g. "level-up", "trade" or "use-item"
+	Trigger       string `json:"trigger"`
+	MinLevel      int    `json:"minLevel,omitempty"`
+	Item          string `json:"item,omitempty"`
+	HeldItem      string `json:"heldItem,omitempty"`
+	TradeSpecies  string `json:"tradeSpecies,omitempty"`
+	MinHappiness  int    `json:"minHappiness,omitempty"`
+	MinAffection  int    `json:"minAffection,omitempty"`
+	TimeOfDay     string `json:"timeOfDay,omitempty"`
+	KnownMoveType string `json:"knownMoveType,omitempty"`
+	Location      string `json:"location,omitempty"`
 }